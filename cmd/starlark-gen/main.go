@@ -0,0 +1,43 @@
+// starlark-gen generates Starlark builtins for Kubernetes-style API types,
+// with the same flags as the k8s.io/gengo generators (deepcopy-gen,
+// openapi-gen, etc), so that it fits into a hack/update-codegen.sh script:
+//
+//	starlark-gen \
+//	  --input-dirs github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1 \
+//	  --output-package github.com/tilt-dev/tilt/internal/tiltfile \
+//	  --output-base "${GOPATH}/src"
+//
+// writes github.com/tilt-dev/tilt/internal/tiltfile/v1alpha1/types.go.
+//
+// For a simpler command line, see tilt-starlark-codegen.
+package main
+
+import (
+	"github.com/spf13/pflag"
+	"k8s.io/gengo/args"
+	"k8s.io/klog/v2"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/pkg/starlarkgen/generators"
+)
+
+func main() {
+	klog.InitFlags(nil)
+	arguments := args.Default()
+
+	// Override defaults.
+	arguments.OutputFileBaseName = "types"
+	arguments.GoHeaderFilePath = ""
+
+	customArgs := generators.DefaultCustomArgs()
+	customArgs.AddFlags(pflag.CommandLine)
+	arguments.CustomArgs = customArgs
+
+	if err := arguments.Execute(
+		generators.NameSystems(),
+		generators.DefaultNameSystem(),
+		generators.Packages,
+	); err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+	klog.V(2).Info("Completed successfully.")
+}
@@ -4,8 +4,12 @@ go 1.16
 
 require (
 	github.com/iancoleman/strcase v0.2.0
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.7.0
+	go.starlark.net v0.0.0-20210312235212-74c10e2c17dc
 	golang.org/x/tools v0.0.0-20210106214847-113979e3529a
 	k8s.io/apimachinery v0.22.2
 	k8s.io/gengo v0.0.0-20210813121822-485abfe95c7c
+	k8s.io/klog/v2 v2.9.0
 )
@@ -0,0 +1,267 @@
+// Package cli implements the tilt-starlark-codegen command line.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+// Returned by a command when it has already printed its own failure message,
+// and only needs to exit non-zero.
+var errSilentExit = errors.New("exit 1")
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+var commands = []command{
+	{
+		name:    "generate",
+		args:    "<input-dir> <output-dir>",
+		summary: "Generate Starlark builtins for the API types in input-dir",
+		run:     runGenerate,
+	},
+	{
+		name:    "verify",
+		args:    "<input-dir> <output-dir>",
+		summary: "Exit non-zero if the generated file in output-dir is out of date",
+		run:     runVerify,
+	},
+	{
+		name:    "list",
+		args:    "<input-dir>",
+		summary: "List the builtins that would be generated",
+		run:     runList,
+	},
+	{
+		name:    "explain",
+		args:    "<input-dir> <type>",
+		summary: "Describe the arguments of the builtin for a type",
+		run:     runExplain,
+	},
+}
+
+// The environment a command runs in.
+type env struct {
+	bin    string
+	cmd    command
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Runs the command line and returns the process exit code.
+func Main(args []string, stdout, stderr io.Writer) int {
+	e := &env{
+		bin:    filepath.Base(args[0]),
+		stdout: stdout,
+		stderr: stderr,
+	}
+	args = args[1:]
+
+	if len(args) == 0 {
+		e.printUsage()
+		return 1
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		e.printUsage()
+		return 0
+	}
+
+	cmd, ok := findCommand(name)
+	if ok {
+		args = args[1:]
+	} else if strings.HasPrefix(name, "-") || len(args) != 2 {
+		fmt.Fprintf(stderr, "%s: unknown command %q\n\n", e.bin, name)
+		e.printUsage()
+		return 1
+	} else {
+		// Before we had subcommands, the only invocation was:
+		// tilt-starlark-codegen <input-dir> <output-dir>
+		cmd, _ = findCommand("generate")
+	}
+
+	e.cmd = cmd
+	err := cmd.run(e, args)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err == errSilentExit {
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func (e *env) printUsage() {
+	fmt.Fprintf(e.stderr, `Generates Starlark functions based on Kubernetes-style API models.
+
+Usage:
+  %s <command> [flags] <args>
+
+Commands:
+`, e.bin)
+	for _, cmd := range commands {
+		fmt.Fprintf(e.stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(e.stderr, `
+Examples:
+  # In the Tilt codebase
+  %[1]s generate ./pkg/apis/core/v1alpha1 ./internal/tiltfile/v1alpha1
+
+  # Dry run (print to stdout)
+  %[1]s generate --stdout ./pkg/apis/core/v1alpha1
+
+  # Fail if the generated code is stale
+  %[1]s verify ./pkg/apis/core/v1alpha1 ./internal/tiltfile/v1alpha1
+
+Run '%[1]s <command> --help' for the flags of each command.
+`, e.bin)
+}
+
+// Creates a flag set for the current command that prints errors and usage to stderr.
+func (e *env) newFlagSet() *flag.FlagSet {
+	cmd := e.cmd
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "%s\n\nUsage:\n  %s %s [flags] %s\n\nFlags:\n",
+			cmd.summary, e.bin, cmd.name, cmd.args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// Parses flags and checks the number of positional args.
+//
+// Go's flag package stops at the first positional arg, so we keep parsing
+// after each one to allow flags anywhere on the command line.
+func (e *env) parseArgs(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	positional := []string{}
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) < minArgs || len(positional) > maxArgs {
+		fmt.Fprintf(e.stderr, "%s: wrong number of arguments\n\n", fs.Name())
+		fs.Usage()
+		return nil, errSilentExit
+	}
+	return positional, nil
+}
+
+// Flags shared by all commands that load API types.
+type typeFlags struct {
+	types        stringListFlag
+	argNames     string
+	acronyms     mapFlag
+	initialisms  stringListFlag
+	renames      mapFlag
+	namingConfig string
+	verbose      bool
+}
+
+func (f *typeFlags) register(fs *flag.FlagSet) {
+	f.acronyms = mapFlag{values: map[string]string{}, format: "GoName=starlarkName"}
+	f.renames = mapFlag{values: map[string]string{}, format: "Type.Field=name"}
+	fs.Var(&f.types, "types", "Comma-separated list of top-level types to generate builtins for (default: all tagged types)")
+	fs.StringVar(&f.argNames, "arg-names", "", "Where argument names come from: 'go' for the snake case of the Go field names (default), or 'json' for the snake case of the json tags")
+	fs.Var(f.acronyms, "acronym", "Acronym to use when converting Go names, as GoName=starlarkName (e.g., UIButton=uiButton). May be repeated")
+	fs.Var(&f.initialisms, "initialism", "Comma-separated list of initialisms to convert as a single word, in addition to the defaults (e.g., IPs, so that PodIPs becomes pod_ips). May be repeated")
+	fs.Var(f.renames, "rename", "Argument name to use instead of the default, as Type.Field=name (e.g., WidgetSpec.Name=widget_name). May be repeated")
+	fs.StringVar(&f.namingConfig, "naming-config", "", "JSON file of acronyms, initialisms, and renames. Flags are added on top")
+	fs.BoolVar(&f.verbose, "v", false, "Print progress messages to stderr")
+}
+
+func (f *typeFlags) options(e *env, inputDir string) codegen.Options {
+	opts := codegen.Options{
+		InputPackage: inputDir,
+		Types:        f.types,
+		ArgNames:     codegen.ArgNameSource(f.argNames),
+		Acronyms:     f.acronyms.values,
+		Initialisms:  f.initialisms,
+		Renames:      f.renames.values,
+		NamingConfig: f.namingConfig,
+	}
+	if f.verbose {
+		opts.Logf = func(format string, args ...interface{}) {
+			fmt.Fprintf(e.stderr, format+"\n", args...)
+		}
+	}
+	return opts
+}
+
+// A flag that accepts a comma-separated list, and may be repeated.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(v string) error {
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			*f = append(*f, item)
+		}
+	}
+	return nil
+}
+
+// A flag that accepts key=value pairs, and may be repeated.
+type mapFlag struct {
+	values map[string]string
+
+	// The form of a pair, for error messages, e.g., GoName=starlarkName.
+	format string
+}
+
+func (f mapFlag) String() string {
+	pairs := []string{}
+	for k, v := range f.values {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f mapFlag) Set(v string) error {
+	parts := strings.SplitN(v, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("expected %s, got %q", f.format, v)
+	}
+	f.values[parts[0]] = parts[1]
+	return nil
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+func runExplain(e *env, args []string) error {
+	fs := e.newFlagSet()
+	flags := &typeFlags{}
+	flags.register(fs)
+
+	args, err := e.parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	opts := flags.options(e, args[0])
+	b, err := codegen.LoadBindings(opts)
+	if err != nil {
+		return err
+	}
+
+	// Accept either the Go type name or the builtin name.
+	name := args[1]
+	matches := func(builtin *codegen.Builtin) bool {
+		goName := builtin.Type.Name.Name
+		return name == goName || name == builtin.Name || name == strings.TrimPrefix(builtin.Name, b.Pkg.Name+".")
+	}
+
+	for _, o := range b.Objects {
+		if matches(&o.Builtin) {
+			return codegen.WriteObjectExplanation(o, b.Pkg, e.stdout)
+		}
+	}
+	for _, s := range b.Structs {
+		if matches(&s.Builtin) {
+			return codegen.WriteStructExplanation(s, b.Pkg, e.stdout)
+		}
+	}
+	return fmt.Errorf("no builtin for type %s. Run '%s list %s' to see all builtins", name, e.bin, args[0])
+}
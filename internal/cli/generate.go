@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+// Flags for commands that produce generated code.
+type outputFlags struct {
+	typeFlags
+	packageName     string
+	fileName        string
+	registerFunc    string
+	externalStructs stringListFlag
+	runtime         string
+	starkitPackage  string
+	valuePackage    string
+	templateDir     string
+	typeCheck       bool
+}
+
+func (f *outputFlags) register(fs *flag.FlagSet) {
+	f.typeFlags.register(fs)
+	fs.StringVar(&f.packageName, "package", "", "Name of the generated Go package (default: the name of the input package)")
+	fs.StringVar(&f.fileName, "file-name", codegen.DefaultOutputFileName, "Name of the generated file in the output directory")
+	fs.StringVar(&f.runtime, "runtime", "tilt", "Runtime helpers the generated code calls: 'tilt' for Tilt's internal packages, or 'standalone' for this repo's pkg/starlarkrt")
+	fs.StringVar(&f.starkitPackage, "starkit-package", "", "Import path of the runtime package that provides UnpackArgs and Environment (overrides --runtime)")
+	fs.StringVar(&f.valuePackage, "value-package", "", "Import path of the runtime package that provides StringList, StringStringMap, LocalPath, LocalPathList and Duration (overrides --runtime)")
+	fs.BoolVar(&f.typeCheck, "typecheck", false, "Type-check the generated code, and fail without writing it if it doesn't compile or can't be checked")
+	fs.StringVar(&f.templateDir, "templates", "", "Directory of templates that override the default ones, named after the template they replace (e.g., attr.tmpl)")
+	fs.StringVar(&f.registerFunc, "register-func", codegen.DefaultRegisterFunc, "Name of the generated Plugin method that registers the builtins. Must be unique when generating several files into one package")
+	fs.Var(&f.externalStructs, "external-structs", "Comma-separated list of nested structs that another file in the output package generates. The generated code uses their types, but doesn't declare them or register their builtins")
+}
+
+func (f *outputFlags) options(e *env, inputDir string) (codegen.Options, error) {
+	opts := f.typeFlags.options(e, inputDir)
+	opts.OutputPackage = f.packageName
+	opts.RegisterFunc = f.registerFunc
+	opts.ExternalStructs = f.externalStructs
+	opts.FileName = f.fileName
+	opts.TypeCheck = f.typeCheck
+	opts.TemplateDir = f.templateDir
+
+	runtime, err := codegen.RuntimeByName(f.runtime)
+	if err != nil {
+		return codegen.Options{}, fmt.Errorf("--%v", err)
+	}
+	opts.Runtime = runtime
+	if f.starkitPackage != "" {
+		opts.Runtime.StarkitPackage = f.starkitPackage
+	}
+	if f.valuePackage != "" {
+		opts.Runtime.ValuePackage = f.valuePackage
+	}
+	return opts, nil
+}
+
+// Runs the generator, and reports problems with the generated code.
+//
+// Problems only fail the command if we were asked to type-check the code.
+func (e *env) generate(opts codegen.Options) ([]byte, error) {
+	result, err := codegen.Generate(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range result.Diagnostics {
+		fmt.Fprintln(e.stderr, d)
+	}
+	if opts.TypeCheck && result.HasErrors() {
+		return nil, fmt.Errorf("generated code failed type-checking")
+	}
+	return result.Source, nil
+}
+
+func runGenerate(e *env, args []string) error {
+	fs := e.newFlagSet()
+	flags := &outputFlags{}
+	flags.register(fs)
+	toStdout := fs.Bool("stdout", false, "Print the generated code to stdout instead of writing it to the output directory")
+	verify := fs.Bool("verify", false, "Don't write anything. Instead, exit non-zero with a diff if the existing file is out of date")
+	diff := fs.Bool("diff", false, "Don't write anything. Instead, print a diff of what would change in the existing file")
+
+	args, err := e.parseArgs(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	// "-" is the legacy spelling of --stdout.
+	if len(args) == 2 && args[1] == "-" {
+		*toStdout = true
+		args = args[:1]
+	}
+	if *toStdout && len(args) == 2 {
+		return fmt.Errorf("--stdout cannot be combined with an output directory")
+	}
+	if *verify && *diff {
+		return fmt.Errorf("--verify cannot be combined with --diff")
+	}
+	if *toStdout && (*verify || *diff) {
+		return fmt.Errorf("--stdout cannot be combined with --verify or --diff")
+	}
+	if !*toStdout && len(args) == 1 {
+		return fmt.Errorf("missing output directory (or --stdout)")
+	}
+
+	opts, err := flags.options(e, args[0])
+	if err != nil {
+		return err
+	}
+
+	result, err := e.generate(opts)
+	if err != nil {
+		return err
+	}
+
+	if *verify {
+		return e.verify(args[1], flags.fileName, result)
+	}
+	if *diff {
+		return e.diff(args[1], flags.fileName, result)
+	}
+
+	// Unless we type-checked the code, if we have a diagnostic,
+	// we should still treat this as success and write to the file anyway.
+	// The user will see an error downstream when they
+	// try to compile the code, and giving them the code
+	// makes it easier to see what went wrong.
+	if *toStdout {
+		_, err = e.stdout.Write(result)
+		return err
+	}
+
+	outName, err := codegen.WriteOutputFile(args[1], flags.fileName, result)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stderr, "Wrote output to %s\n", outName)
+	return nil
+}
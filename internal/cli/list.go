@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+func runList(e *env, args []string) error {
+	fs := e.newFlagSet()
+	flags := &typeFlags{}
+	flags.register(fs)
+
+	args, err := e.parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	opts := flags.options(e, args[0])
+	b, err := codegen.LoadBindings(opts)
+	if err != nil {
+		return err
+	}
+
+	return codegen.WriteBuiltinList(b, e.stdout)
+}
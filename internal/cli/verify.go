@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+func runVerify(e *env, args []string) error {
+	fs := e.newFlagSet()
+	flags := &outputFlags{}
+	flags.register(fs)
+
+	args, err := e.parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	opts, err := flags.options(e, args[0])
+	if err != nil {
+		return err
+	}
+
+	result, err := e.generate(opts)
+	if err != nil {
+		return err
+	}
+
+	return e.verify(args[1], flags.fileName, result)
+}
+
+// Compares the generated code with the file in the output directory.
+//
+// If they differ, prints a unified diff to stdout and fails.
+func (e *env) verify(outDir, fileName string, result []byte) error {
+	outPath := filepath.Join(outDir, fileName)
+	diff, exists, err := diffOutput(outPath, result)
+	if err != nil {
+		return err
+	}
+
+	if exists && diff == "" {
+		fmt.Fprintf(e.stderr, "%s is up to date\n", outPath)
+		return nil
+	}
+
+	_, err = fmt.Fprint(e.stdout, diff)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		fmt.Fprintf(e.stderr, "%s does not exist.", outPath)
+	} else {
+		fmt.Fprintf(e.stderr, "%s is out of date.", outPath)
+	}
+	// Don't suggest a command line, since it would need all the same flags
+	// to generate the same file.
+	fmt.Fprintf(e.stderr, " Re-run '%s generate' with the same flags to update it.\n", e.bin)
+	return errSilentExit
+}
+
+// Prints a unified diff of what regenerating the file would change.
+//
+// Unlike verify, a diff is not a failure.
+func (e *env) diff(outDir, fileName string, result []byte) error {
+	outPath := filepath.Join(outDir, fileName)
+	diff, exists, err := diffOutput(outPath, result)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		fmt.Fprintf(e.stderr, "%s does not exist yet\n", outPath)
+	} else if diff == "" {
+		fmt.Fprintf(e.stderr, "No changes to %s\n", outPath)
+	}
+
+	_, err = fmt.Fprint(e.stdout, diff)
+	return err
+}
+
+// Diffs the current contents of the output file against the generated code.
+// A missing file is treated as empty.
+func diffOutput(outPath string, result []byte) (diff string, exists bool, err error) {
+	existing, err := ioutil.ReadFile(outPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", false, err
+	}
+	exists = err == nil
+
+	diff, err = codegen.UnifiedDiff(outPath, existing, result)
+	if err != nil {
+		return "", false, err
+	}
+	return diff, exists, nil
+}
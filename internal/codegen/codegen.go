@@ -3,30 +3,49 @@ package codegen
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
-	"github.com/iancoleman/strcase"
 	"k8s.io/gengo/parser"
 	"k8s.io/gengo/types"
 )
 
 // Find all top-level types with the tilt:starlark-gen=true tag.
+//
+// The package may be a directory or an import path.
 func LoadStarlarkGenTypes(pkg string) (*types.Package, []*types.Type, error) {
+	importPath, err := resolveImportPath(pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	b := parser.New()
 	u := types.Universe{}
-	pkgSpec, err := b.AddDirectoryTo(pkg, &u)
+	pkgSpec, err := b.AddDirectoryTo(importPath, &u)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	results, err := FindStarlarkGenTypes(pkgSpec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkgSpec, results, nil
+}
+
+// Find all the types in an already-loaded package with the
+// tilt:starlark-gen=true tag, sorted by name.
+func FindStarlarkGenTypes(pkg *types.Package) ([]*types.Type, error) {
 	results := []*types.Type{}
-	for _, t := range pkgSpec.Types {
+	for _, t := range pkg.Types {
 		ok, err := types.ExtractSingleBoolCommentTag("+", "tilt:starlark-gen", false, t.CommentLines)
 		if err != nil {
-			return nil, nil, fmt.Errorf("parsing tags in %s: %v", t, err)
+			return nil, fmt.Errorf("parsing tags in %s: %v", t, err)
 		}
 		if ok {
 			results = append(results, t)
@@ -35,8 +54,7 @@ func LoadStarlarkGenTypes(pkg string) (*types.Package, []*types.Type, error) {
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Name.Name < results[j].Name.Name
 	})
-
-	return pkgSpec, results, nil
+	return results, nil
 }
 
 func getSpecMemberType(t *types.Type) *types.Type {
@@ -58,581 +76,169 @@ func getDataMember(t *types.Type) *types.Member {
 	return nil
 }
 
-// Opens the output file.
-func OpenOutputFile(outDir string) (w io.Writer, path string, err error) {
-	if outDir == "-" {
-		return os.Stdout, "stdout", nil
-	}
+// The default name of the generated file.
+const DefaultOutputFileName = "types.go"
 
-	outPath := filepath.Join(outDir, "types.go")
+// The default name of the generated method that registers all the builtins.
+const DefaultRegisterFunc = "registerSymbols"
 
-	out, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0555)
-	if err != nil {
-		return nil, outPath, err
+// The prefix of the generated helper types, like Int32List and Int64Map, in the file
+// with the given register func.
+//
+// Each file generated into a package declares its own helpers, so files
+// with a custom register func prefix them with its name, minus "register",
+// e.g., RoutesInt32List for registerRoutes. Files with the default register
+// func don't.
+func HelperPrefix(registerFunc string) string {
+	if registerFunc == "" || registerFunc == DefaultRegisterFunc {
+		return ""
 	}
-	return out, outPath, nil
-}
-
-// Writes the package header.
-func WritePreamble(pkg *types.Package, w io.Writer) error {
-
-	_, err := fmt.Fprintf(w, `package %s
-
-import (
-	"go.starlark.net/starlark"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
-	"github.com/tilt-dev/tilt/internal/tiltfile/value"
-	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
-)
-
-// AUTOGENERATED by github.com/tilt-dev/tilt-starlark-codegen
-// DO NOT EDIT MANUALLY
-`, pkg.Name)
-	if err != nil {
-		return err
+	name := registerFunc
+	if rest := strings.TrimPrefix(name, "register"); rest != "" && unicode.IsUpper([]rune(rest)[0]) {
+		name = rest
 	}
-	return nil
+	return strings.ToUpper(name[:1]) + name[1:]
 }
 
-// Writes a function that registers all the starlark methods.
-func WriteStarlarkRegistrationFunc(types []*types.Type, pkg *types.Package, w io.Writer) error {
-	_, err := fmt.Fprintf(w, `
-func (p Plugin) registerSymbols(env *starkit.Environment) error {
-  var err error
-`)
-	if err != nil {
-		return err
-	}
-
-	for _, t := range types {
-		tName := t.Name.Name
-		_, err := fmt.Fprintf(w, `
-	err = env.AddBuiltin("%s.%s", p.%s)
-	if err != nil {
-		return err
-	}`, pkg.Name, strcase.ToSnake(tName), strcase.ToLowerCamel(tName))
-		if err != nil {
-			return err
-		}
+// Writes the output file.
+//
+// Writes to a temp file in the same directory first, then renames it
+// over the old file, so that a failed write never leaves a half-written file.
+func WriteOutputFile(outDir string, fileName string, contents []byte) (path string, err error) {
+	if fileName == "" {
+		fileName = DefaultOutputFileName
 	}
-
-	_, err = fmt.Fprintf(w, `
-  return nil
-}`)
-	return err
-}
-
-func unpackMemberVarName(m types.Member) string {
-	if m.Name == "Args" {
-		return "specArgs"
+	if filepath.Base(fileName) != fileName || filepath.Ext(fileName) != ".go" {
+		return "", fmt.Errorf("output file name must be a .go file name without a directory, got %q", fileName)
 	}
-	if m.Name == "Labels" {
-		return "specLabels"
-	}
-	if m.Name == "Annotations" {
-		return "specAnnotations"
-	}
-	return strcase.ToLowerCamel(m.Name)
-}
+	outPath := filepath.Join(outDir, fileName)
 
-type memberVar struct {
-	Type    string
-	Name    string
-	Initial string
-}
-
-// Helper function to determine how to unpack fields.
-func unpackMemberVar(m types.Member) (memberVar, error) {
-	isLocalPath, err := types.ExtractSingleBoolCommentTag("+", "tilt:local-path", false, m.CommentLines)
+	tmp, err := ioutil.TempFile(outDir, "."+fileName+".tmp-")
 	if err != nil {
-		return memberVar{}, fmt.Errorf("parsing tags in %s: %v", m.Name, err)
-	}
-	isDuration := isDurationMember(m)
-
-	mName := unpackMemberVarName(m)
-	t := m.Type
-
-	if t.Kind == types.Builtin {
-		if isLocalPath {
-			return memberVar{
-				Type: "value.LocalPath",
-				Name: mName,
-				Initial: fmt.Sprintf(`= value.NewLocalPathUnpacker(t)
-  err = %s.Unpack(starlark.String(""))
-  if err != nil {
-    return nil, err
-  }
-`, mName),
-			}, nil
-		}
-
-		return memberVar{Name: fmt.Sprintf("obj.Spec.%s", m.Name)}, nil
-	}
-
-	if t.Kind == types.Alias && t.Underlying.Kind == types.Builtin {
-		return memberVar{
-			Type: t.Underlying.Name.Name,
-			Name: mName,
-		}, nil
-	}
-
-	if t.Kind == types.Struct {
-		if isDuration {
-			return memberVar{
-				Type: "value.Duration",
-				Name: unpackMemberVarName(m),
-			}, nil
-		}
-		return memberVar{
-			Type:    t.Name.Name,
-			Name:    unpackMemberVarName(m),
-			Initial: fmt.Sprintf("= %s{t: t}", t.Name.Name),
-		}, nil
+		return outPath, err
 	}
-
-	if t.Kind == types.Pointer {
-		if t.Elem.Kind == types.Struct {
-			return memberVar{
-				Type:    t.Elem.Name.Name,
-				Name:    unpackMemberVarName(m),
-				Initial: fmt.Sprintf("= %s{t: t}", t.Elem.Name.Name),
-			}, nil
-		}
-	}
-
-	if t.Kind == types.Map {
-		return memberVar{
-			Type: "value.StringStringMap",
-			Name: unpackMemberVarName(m),
-		}, nil
-	}
-
-	if t.Kind == types.Slice {
-		if t.Elem.Kind == types.Builtin && t.Elem.Name.Name == "string" {
-			if isLocalPath {
-				return memberVar{
-					Type:    "value.LocalPathList",
-					Name:    unpackMemberVarName(m),
-					Initial: "= value.NewLocalPathListUnpacker(t)",
-				}, nil
-			} else {
-				return memberVar{
-					Type: "value.StringList",
-					Name: unpackMemberVarName(m),
-				}, nil
-			}
-		}
-
-		if t.Elem.Kind == types.Struct {
-			return memberVar{
-				Type:    fmt.Sprintf("%sList", t.Elem.Name.Name),
-				Name:    unpackMemberVarName(m),
-				Initial: fmt.Sprintf("= %sList{t: t}", t.Elem.Name.Name),
-			}, nil
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
 		}
-	}
-	return memberVar{}, fmt.Errorf("Cannot unpack member %s", m.Name)
-}
-
-func modelTypeName(t *types.Type) string {
-	if strings.Contains(t.Name.Package, "/meta") {
-		return fmt.Sprintf("metav1.%s", t.Name.Name)
-	}
+	}()
 
-	parts := strings.Split(t.Name.Package, "/")
-	return fmt.Sprintf("%s.%s", parts[len(parts)-1], t.Name.Name)
-}
-
-// Given a gengo Type, create a starlark function that reads that type.
-func WriteStarlarkAPIObjectFunction(t *types.Type, pkg *types.Package, w io.Writer) error {
-	tName := t.Name.Name
-	fnName := strcase.ToLowerCamel(tName)
-	spec := getSpecMemberType(t)
-	data := getDataMember(t)
-	var members []types.Member
-	if spec != nil {
-		members = spec.Members
-	} else if data != nil {
-		members = []types.Member{*data}
-	} else {
-		return fmt.Errorf("type has no spec or data field: %s", tName)
-	}
-
-	objTypeName := modelTypeName(t)
-
-	// Print the function signature.
-	_, err := fmt.Fprintf(w, `
-func (p Plugin) %s(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {`,
-		fnName)
+	_, err = tmp.Write(contents)
 	if err != nil {
-		return err
+		return outPath, err
 	}
 
-	// Print the object initializer.
-	specInit := ""
-	if spec != nil {
-		specInit = fmt.Sprintf(`
-    Spec: %sSpec{},`, objTypeName)
-	}
-	_, err = fmt.Fprintf(w, `
-  var err error
-	obj := &%s{
-		ObjectMeta: metav1.ObjectMeta{},%s
-	}`, objTypeName, specInit)
+	// TempFile creates files that only the owner can read.
+	err = tmp.Chmod(0644)
 	if err != nil {
-		return err
+		return outPath, err
 	}
 
-	// Print any special unpack vars.
-	for _, member := range members {
-		memberVar, err := unpackMemberVar(member)
-		if err != nil {
-			return fmt.Errorf("generating type %s: %v", tName, err)
-		}
-
-		if memberVar.Type == "" {
-			continue
-		}
-
-		_, err = fmt.Fprintf(w, `
-  var %s %s %s`, memberVar.Name, memberVar.Type, memberVar.Initial)
-		if err != nil {
-			return err
-		}
-	}
-
-	// Print the object unpacker.
-	_, err = fmt.Fprintf(w, `
-  var labels value.StringStringMap
-	var annotations value.StringStringMap
-	err = starkit.UnpackArgs(t, fn.Name(), args, kwargs,
-		"name", &obj.ObjectMeta.Name,
-		"labels?", &labels,
-		"annotations?", &annotations,`)
+	err = tmp.Close()
 	if err != nil {
-		return err
-	}
-
-	// Print unpackers of individual members.
-	for _, member := range members {
-		memberVar, _ := unpackMemberVar(member)
-		_, err = fmt.Fprintf(w, `
-    "%s?", &%s,`, strcase.ToSnake(member.Name), memberVar.Name)
-		if err != nil {
-			return err
-		}
+		return outPath, err
 	}
 
-	// Print the end of arg parsing.
-	_, err = fmt.Fprintf(w, `
-  )
-  if err != nil {
-    return nil, err
-  }
-`)
+	err = os.Rename(tmp.Name(), outPath)
 	if err != nil {
-		return err
+		return outPath, err
 	}
+	return outPath, nil
+}
 
-	// Copy unpackers into the object.
-	for _, member := range members {
-		memberVar, _ := unpackMemberVar(member)
-		unpackOptional := false
-		if memberVar.Type == "" {
-			continue
-		}
-		varN := memberVar.Name
-		if memberVar.Initial != "" {
-			varN = varN + ".Value"
-		}
-		if member.Type.Kind == types.Pointer {
-			varN = "&" + varN
-			if member.Type.Elem.Kind == types.Struct {
-				varN = fmt.Sprintf("(*%s)(%s)", modelTypeName(member.Type.Elem), varN)
-				unpackOptional = true
-			}
-		}
-
-		if member.Type.Kind == types.Struct || member.Type.Kind == types.Alias {
-			isDuration := isDurationMember(member)
-			if isDuration {
-				varN = fmt.Sprintf("metav1.Duration{Duration: time.Duration(%s)}", varN)
-			} else {
-				varN = fmt.Sprintf("%s(%s)", modelTypeName(member.Type), varN)
-			}
-		}
+// Writes the package header, with imports for everything
+// the rest of the file referenced.
+func WritePreamble(pkgName string, c *Context, w io.Writer) error {
+	first, second := c.ImportGroups()
+	return c.execute(w, "preamble", struct {
+		Package       string
+		FirstImports  []string
+		SecondImports []string
+	}{pkgName, first, second})
+}
 
-		if unpackOptional {
-			_, err = fmt.Fprintf(w, `
-    if %s.isUnpacked {`, unpackMemberVarName(member))
-			if err != nil {
-				return err
-			}
-		}
+// Writes a function that registers all the starlark methods.
+func WriteStarlarkRegistrationFunc(b *Bindings, c *Context, funcName string, w io.Writer) error {
+	return c.execute(w, "register", struct {
+		FuncName string
+		Builtins []*Builtin
+	}{funcName, b.Builtins()})
+}
 
-		if spec != nil {
-			_, err = fmt.Fprintf(w, `
-    obj.Spec.%s = %s`, member.Name, varN)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err = fmt.Fprintf(w, `
-    obj.%s = %s`, member.Name, varN)
-			if err != nil {
-				return err
-			}
+// The Go expression that converts an unpacked variable to the type of
+// the field, where v is the variable or the Value of its unpacker.
+func convertValue(conv *Converter, v string, c *Context) string {
+	switch conv.Kind {
+	case DurationConverter:
+		return fmt.Sprintf("metav1.Duration{Duration: time.Duration(%s)}", v)
+	case StructConverter:
+		if conv.Pointer() {
+			return fmt.Sprintf("(*%s)(&%s)", c.TypeName(conv.Struct.Type), v)
 		}
-
-		if unpackOptional {
-			_, err = fmt.Fprintf(w, `
-    }`)
-			if err != nil {
-				return err
-			}
+		return fmt.Sprintf("%s(%s)", c.TypeName(conv.Struct.Type), v)
+	case StringMapConverter:
+		if conv.Named() == nil {
+			return fmt.Sprintf("(map[string]string)(%s)", v)
 		}
 	}
-
-	// Register the type.
-	_, err = fmt.Fprintf(w, `
-  obj.ObjectMeta.Labels = labels
-  obj.ObjectMeta.Annotations = annotations
-	return p.register(t, obj)
-}
-`)
-	if err != nil {
-		return err
+	if named := conv.Named(); named != nil {
+		return fmt.Sprintf("%s(%s)", c.TypeName(named), v)
 	}
-	return nil
+	return v
+}
+
+// Given an object, create a starlark function that reads that type.
+func WriteStarlarkAPIObjectFunction(o *Object, c *Context, w io.Writer) error {
+	return c.execute(w, "object", o)
 }
 
 // Given a member list struct type, we need to 2 pieces:
 // 1) A starlark type so that this struct can be passed around.
 // 2) An Unpack() function so that this struct can be read from a list.
-func WriteStarlarkStructListFunction(t *types.Type, pkg *types.Package, w io.Writer) error {
-
-	tName := t.Name.Name
-
-	// Embed a frozen list so this can be accessed.
-	_, err := fmt.Fprintf(w, `
-type %sList struct {
-  *starlark.List
-  Value []%s
-  t *starlark.Thread
+func WriteStarlarkStructListFunction(s *Struct, c *Context, w io.Writer) error {
+	return c.execute(w, "list", s)
 }
 
-func (o *%sList) Unpack(v starlark.Value) error {
-	items := []%s{}
-
-  listObj, ok := v.(*starlark.List)
-  if !ok {
-    return fmt.Errorf("expected list, actual: %%v", v.Type())
-  }
-
-  for i := 0; i < listObj.Len(); i++ {
-    v := listObj.Index(i)
-
-    item := %s{t: o.t}
-    err := item.Unpack(v)
-    if err != nil {
-      return fmt.Errorf("at index %%d: %%v", i, err)
-    }
-    items = append(items, %s(item.Value))
-  }
-
-  listObj.Freeze()
-  o.List = listObj
-  o.Value = items
+// Writes the list type for a slice of bools or numbers, and the Unpack()
+// function that reads it from a list or tuple.
+func WriteStarlarkScalarListFunction(l *ScalarList, c *Context, w io.Writer) error {
+	return c.execute(w, "scalarlist", l)
+}
 
-  return nil
-}`, tName, modelTypeName(t), tName, modelTypeName(t), tName, modelTypeName(t))
-	if err != nil {
-		return err
-	}
-	return nil
+// Writes the dict type for a map with values other than strings, and the
+// Unpack() function that reads it from a dict.
+func WriteStarlarkMapFunction(m *Map, c *Context, w io.Writer) error {
+	return c.execute(w, "map", m)
 }
 
 // Given a member struct type, we need to 3 pieces:
 // 1) A starlark type so that this struct can be passed around.
 // 2) An Unpack() function so that this struct can be read from a dict.
 // 3) A built-in function that constructs the object natively.
-func WriteStarlarkStructFunction(t *types.Type, pkg *types.Package, w io.Writer) error {
-
-	tName := t.Name.Name
-
-	// The built-in struct is composed of
-	// 1) An embedded, frozen Dict
-	// 2) A first-class representation of the API type.
-	_, err := fmt.Fprintf(w, `
-type %s struct {
-  *starlark.Dict
-  Value %s
-  isUnpacked bool
-  t *starlark.Thread // instantiation thread for computing abspath
+func WriteStarlarkStructFunction(s *Struct, c *Context, w io.Writer) error {
+	return c.execute(w, "struct", s)
 }
-`, tName, modelTypeName(t))
-	if err != nil {
-		return err
-	}
 
-	err = writeStarlarkStructConstructor(t, pkg, w)
-	if err != nil {
-		return err
+// Whether a member is tagged +tilt:starlark-skip, which leaves it out of
+// the builtins and unpackers. The tag may be bare or have a bool value,
+// e.g., +tilt:starlark-skip=true.
+func isSkippedMember(m types.Member) (bool, error) {
+	values := types.ExtractCommentTags("+", m.CommentLines)["tilt:starlark-skip"]
+	if len(values) == 0 {
+		return false, nil
 	}
-
-	return writeStarlarkStructUnpacker(t, pkg, w)
-}
-
-// For each struct in the API that's not a top-level type, create
-// a built-in function that returns the struct and lets you pass it around.
-func writeStarlarkStructConstructor(t *types.Type, pkg *types.Package, w io.Writer) error {
-	tName := t.Name.Name
-	fnName := strcase.ToLowerCamel(tName)
-
-	// Print the function signature.
-	_, err := fmt.Fprintf(w, `
-func (p Plugin) %s(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {`,
-		fnName)
-	if err != nil {
-		return err
+	if len(values) > 1 {
+		return false, fmt.Errorf("member %s has more than one +tilt:starlark-skip tag", m.Name)
 	}
-
-	// Unpack each argument into a starlark.Value
-	for _, m := range t.Members {
-		_, err = fmt.Fprintf(w, `
-  var %s starlark.Value`, unpackMemberVarName(m))
-		if err != nil {
-			return err
-		}
+	if values[0] == "" {
+		return true, nil
 	}
-
-	_, err = fmt.Fprintf(w, `
-	err := starkit.UnpackArgs(t, fn.Name(), args, kwargs,`)
+	skip, err := strconv.ParseBool(values[0])
 	if err != nil {
-		return err
+		return false, fmt.Errorf("parsing tags in %s: +tilt:starlark-skip=%s is not a bool", m.Name, values[0])
 	}
-
-	for _, member := range t.Members {
-		_, err = fmt.Fprintf(w, `
-    "%s?", &%s,`, strcase.ToSnake(member.Name), unpackMemberVarName(member))
-		if err != nil {
-			return err
-		}
-	}
-
-	// Print the end of arg parsing.
-	_, err = fmt.Fprintf(w, `
-  )
-  if err != nil {
-    return nil, err
-  }
-`)
-	if err != nil {
-		return err
-	}
-
-	// Create a dict from the args, then use dict-based unpacking.
-	_, err = fmt.Fprintf(w, `
-  dict := starlark.NewDict(%d)
-`, len(t.Members))
-	if err != nil {
-		return err
-	}
-
-	for _, member := range t.Members {
-		mName := unpackMemberVarName(member)
-		_, err = fmt.Fprintf(w, `
-  if %s != nil {
-    err := dict.SetKey(starlark.String("%s"), %s)
-    if err != nil {
-      return nil, err
-    }
-  }`, mName,
-			strcase.ToSnake(member.Name),
-			mName)
-		if err != nil {
-			return err
-		}
-	}
-
-	_, err = fmt.Fprintf(w, `
-  var obj *%s = &%s{t: t}
-  err = obj.Unpack(dict)
-  if err != nil {
-    return nil, err
-  }
-  return obj, nil
-}
-`, tName, tName)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func writeStarlarkStructUnpacker(t *types.Type, pkg *types.Package, w io.Writer) error {
-	tName := t.Name.Name
-
-	// Print the Unpack() signature.
-	_, err := fmt.Fprintf(w, `
-func (o *%s) Unpack(v starlark.Value) error {`,
-		tName)
-	if err != nil {
-		return err
-	}
-
-	// Zero out the object, and start iterating over the value.
-	_, err = fmt.Fprintf(w, `
-	obj := %s{}
-
-  starlarkObj, ok := v.(*%s)
-  if ok {
-    *o = *starlarkObj
-    return nil
-  }
-
-  mapObj, ok := v.(*starlark.Dict)
-  if !ok {
-    return fmt.Errorf("expected dict, actual: %%v", v.Type())
-  }
-
-  for _, item := range mapObj.Items() {
-    keyV, val := item[0], item[1]
-    key, ok := starlark.AsString(keyV)
-    if !ok {
-      return fmt.Errorf("key must be string. Got: %%s", keyV.Type())
-    }
-`, modelTypeName(t), tName)
-	if err != nil {
-		return err
-	}
-
-	// Unpack each attribute.
-	for _, m := range t.Members {
-		err := writeAttrUnpacker(m, pkg, w)
-		if err != nil {
-			return fmt.Errorf("generating %s unpacker: %v", t.Name.Name, err)
-		}
-	}
-
-	_, err = fmt.Fprintf(w, `
-    return fmt.Errorf("Unexpected attribute name: %%s", key)
-  }
-
-  mapObj.Freeze()
-  o.Dict = mapObj
-  o.Value = obj
-  o.isUnpacked = true
-
-  return nil
-}`)
-	if err != nil {
-		return err
-	}
-	return nil
+	return skip, nil
 }
 
 func isTimeMember(m types.Member) bool {
@@ -666,200 +272,3 @@ func isDurationMember(m types.Member) bool {
 	}
 	return false
 }
-
-// Recursive helper function for unpacking individual members
-// of a struct.
-func writeAttrUnpacker(m types.Member, pkg *types.Package, w io.Writer) error {
-	if m.Embedded {
-		for _, embeddedMember := range m.Type.Members {
-			err := writeAttrUnpacker(embeddedMember, pkg, w)
-			if err != nil {
-				return err
-			}
-		}
-		return nil
-	}
-
-	// Skip Time and MicroTime for now.
-	if isTimeMember(m) {
-		return nil
-	}
-
-	_, err := fmt.Fprintf(w, `
-    if key == "%s" {`, strcase.ToSnake(m.Name))
-	if err != nil {
-		return err
-	}
-
-	isLocalPath, err := types.ExtractSingleBoolCommentTag("+", "tilt:local-path", false, m.CommentLines)
-	if err != nil {
-		return fmt.Errorf("parsing tags in %s: %v", m.Name, err)
-	}
-
-	isDuration := isDurationMember(m)
-
-	if m.Type.Kind == types.Builtin ||
-		(m.Type.Kind == types.Alias && m.Type.Underlying.Kind == types.Builtin) ||
-		(m.Type.Kind == types.Pointer && m.Type.Elem.Kind == types.Builtin) {
-		cast := fmt.Sprintf("%s(v)", m.Type.Name.Name)
-		isInt := m.Type.Name.Name == "int32"
-		isBool := m.Type.Name.Name == "bool"
-		if m.Type.Kind == types.Alias {
-			cast = fmt.Sprintf("%s(v)", modelTypeName(m.Type))
-			isInt = m.Type.Underlying.Name.Name == "int32"
-		} else if m.Type.Kind == types.Pointer {
-			cast = fmt.Sprintf("(*%s)(&v)", m.Type.Elem.Name.Name)
-			isInt = m.Type.Elem.Name.Name == "int32"
-		}
-
-		if isBool {
-			// Unpack bools
-			_, err = fmt.Fprintf(w, `
-      v, ok := val.(starlark.Bool)
-      if !ok {
-        return fmt.Errorf("Expected bool, got: %%v", val.Type())
-      }
-      obj.%s = bool(v)
-      continue`, m.Name)
-			if err != nil {
-				return err
-			}
-		} else if isInt {
-			// Unpack ints
-			_, err = fmt.Fprintf(w, `
-      v, err := starlark.AsInt32(val)
-      if err != nil {
-        return fmt.Errorf("Expected int, got: %%v", err)
-      }
-      obj.%s = %s
-      continue`, m.Name, cast)
-			if err != nil {
-				return err
-			}
-		} else if isLocalPath {
-			// Unpack local path lists
-			_, err = fmt.Fprintf(w, `
-      v := value.NewLocalPathUnpacker(o.t)
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = v.Value
-      continue`, m.Name)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Unpack strings
-			_, err = fmt.Fprintf(w, `
-      v, ok := starlark.AsString(val)
-      if !ok {
-        return fmt.Errorf("Expected string, actual: %%s", val.Type())
-      }
-      obj.%s = %s
-      continue`, m.Name, cast)
-			if err != nil {
-				return err
-			}
-		}
-	} else if m.Type.Kind == types.Slice &&
-		m.Type.Elem.Kind == types.Builtin && m.Type.Elem.Name.Name == "string" {
-		if isLocalPath {
-			_, err = fmt.Fprintf(w, `
-      v := value.NewLocalPathListUnpacker(o.t)
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = v
-      continue`, m.Name)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err = fmt.Fprintf(w, `
-      var v value.StringList
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = v
-      continue`, m.Name)
-			if err != nil {
-				return err
-			}
-		}
-	} else if m.Type.Kind == types.Slice &&
-		m.Type.Elem.Kind == types.Struct {
-		_, err = fmt.Fprintf(w, `
-      v := %sList{t: o.t}
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = v.Value
-      continue`, m.Type.Elem.Name.Name, m.Name)
-		if err != nil {
-			return err
-		}
-	} else if m.Type.Kind == types.Struct {
-		if isDuration {
-			_, err = fmt.Fprintf(w, `
-      v := value.Duration{}
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = metav1.Duration{Duration: time.Duration(v)}
-      continue`, m.Name)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err = fmt.Fprintf(w, `
-      v := %s{t: o.t}
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = v.Value
-      continue`, m.Type.Name.Name, m.Name)
-			if err != nil {
-				return err
-			}
-		}
-	} else if m.Type.Kind == types.Pointer && m.Type.Elem.Kind == types.Struct {
-		_, err = fmt.Fprintf(w, `
-      v := %s{t: o.t}
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = (*%s)(&v.Value)
-      continue`, m.Type.Elem.Name.Name, m.Name, modelTypeName(m.Type.Elem))
-		if err != nil {
-			return err
-		}
-	} else if m.Type.Kind == types.Map && m.Type.Elem.Name.Name == "string" && m.Type.Key.Name.Name == "string" {
-		_, err = fmt.Fprintf(w, `
-      var v value.StringStringMap
-      err := v.Unpack(val)
-      if err != nil {
-        return fmt.Errorf("unpacking %%s: %%v", key, err)
-      }
-      obj.%s = (map[string]string)(v)
-      continue`, m.Name)
-		if err != nil {
-			return err
-		}
-	} else {
-		return fmt.Errorf("Unable to unpack attribute %s type %s", m.Name, m.Type)
-	}
-
-	_, err = fmt.Fprintf(w, `
-    }`)
-	if err != nil {
-		return err
-	}
-	return nil
-}
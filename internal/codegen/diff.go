@@ -0,0 +1,17 @@
+package codegen
+
+import (
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Returns a unified diff that turns the current contents of a file into
+// the generated contents. Returns an empty string if they're identical.
+func UnifiedDiff(path string, current []byte, generated []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(string(generated)),
+		FromFile: path,
+		ToFile:   path + " (generated)",
+		Context:  3,
+	})
+}
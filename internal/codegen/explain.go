@@ -0,0 +1,124 @@
+package codegen
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"k8s.io/gengo/types"
+)
+
+// Writes a table of all the builtins we would generate.
+func WriteBuiltinList(b *Bindings, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	_, err := fmt.Fprintf(tw, "BUILTIN\tGO TYPE\tKIND\n")
+	if err != nil {
+		return err
+	}
+
+	for _, o := range b.Objects {
+		_, err := fmt.Fprintf(tw, "%s\t%s\tobject\n", o.Name, o.Type.Name.Name)
+		if err != nil {
+			return err
+		}
+	}
+	for _, s := range b.Structs {
+		_, err := fmt.Fprintf(tw, "%s\t%s\tstruct\n", s.Name, s.Type.Name.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// Writes a human-readable description of the builtin for an object:
+// each argument, what it accepts, and the Go field it's copied into.
+func WriteObjectExplanation(o *Object, pkg *types.Package, w io.Writer) error {
+	return writeExplanation(&o.Builtin, true, pkg, w)
+}
+
+// Writes a human-readable description of the builtin for a struct.
+func WriteStructExplanation(s *Struct, pkg *types.Package, w io.Writer) error {
+	return writeExplanation(&s.Builtin, false, pkg, w)
+}
+
+func writeExplanation(b *Builtin, isObject bool, pkg *types.Package, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s(...) constructs a %s\n\n", b.Name, NewContext(pkg, DefaultRuntime).TypeName(b.Type))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	_, err = fmt.Fprintf(tw, "ARG\tACCEPTS\tFIELD\n")
+	if err != nil {
+		return err
+	}
+
+	if isObject {
+		_, err = fmt.Fprintf(tw, "name\tstring (required)\tObjectMeta.Name\n"+
+			"labels\tdict of string to string\tObjectMeta.Labels\n"+
+			"annotations\tdict of string to string\tObjectMeta.Annotations\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, f := range b.Fields {
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name, describeConverter(f.Converter), f.GoName)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// Replaces embedded members with the members of the embedded struct,
+// the same way the struct unpacker does.
+func flattenEmbedded(members []types.Member) []types.Member {
+	result := []types.Member{}
+	for _, m := range members {
+		if m.Embedded {
+			result = append(result, flattenEmbedded(m.Type.Members)...)
+			continue
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// Describes the Starlark values that a converter accepts.
+func describeConverter(conv *Converter) string {
+	switch conv.Kind {
+	case ScalarConverter:
+		return describeBuiltin(conv.Builtin)
+	case LocalPathConverter:
+		return "path"
+	case DurationConverter:
+		return "duration string (e.g., \"5s\")"
+	case StructConverter:
+		return fmt.Sprintf("%s or dict", conv.Struct.StarlarkType)
+	case StringListConverter:
+		return "list of string"
+	case LocalPathListConverter:
+		return "list of paths"
+	case StructListConverter:
+		return fmt.Sprintf("list of %s or dict", conv.Struct.StarlarkType)
+	case ScalarListConverter:
+		return "list of " + describeBuiltin(conv.List.Elem.Builtin)
+	case StringMapConverter:
+		return "dict of string to string"
+	case MapConverter:
+		return "dict of string to " + describeConverter(conv.Map.Elem)
+	}
+	return conv.Type.String()
+}
+
+func describeBuiltin(t *types.Type) string {
+	switch t.Name.Name {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "int"
+	case "float32", "float64":
+		return "float or int"
+	}
+	return t.Name.Name
+}
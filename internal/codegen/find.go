@@ -7,14 +7,20 @@ import (
 )
 
 // Find all the member types that need custom unpackers.
-func FindStructMembers(topLevelTypes []*types.Type) ([]*types.Type, error) {
+//
+// Members that the naming options skip aren't searched. Neither are the
+// external structs, which are in the result, but their members aren't.
+func FindStructMembers(topLevelTypes []*types.Type, naming NamingOptions, external map[string]bool) ([]*types.Type, error) {
 	resultMap := map[string]*types.Type{}
 	for _, t := range topLevelTypes {
 		spec := getSpecMemberType(t)
 		if spec == nil {
 			continue
 		}
-		findStructMembersHelper(spec, resultMap)
+		err := findStructMembersHelper(spec, naming, external, resultMap)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	result := []*types.Type{}
@@ -29,27 +35,73 @@ func FindStructMembers(topLevelTypes []*types.Type) ([]*types.Type, error) {
 }
 
 // A recursive helper that populates the map with the results if its search.
-func findStructMembersHelper(t *types.Type, result map[string]*types.Type) {
-	recurse := func(candidate *types.Type) {
+//
+// Doesn't look inside skipped members or external structs, so types that are
+// only reachable through them don't get unpackers.
+func findStructMembersHelper(t *types.Type, naming NamingOptions, external map[string]bool, result map[string]*types.Type) error {
+	recurse := func(candidate *types.Type) error {
 		_, exists := result[candidate.Name.Name]
 		if exists {
-			return
+			return nil
 		}
 		result[candidate.Name.Name] = candidate
-		findStructMembersHelper(candidate, result)
+		if external[candidate.Name.Name] {
+			return nil
+		}
+		return findStructMembersHelper(candidate, naming, external, result)
 	}
 
 	for _, m := range t.Members {
 		if isTimeMember(m) || isDurationMember(m) {
 			continue
 		}
+		skip, err := naming.skips(m)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		// Named slices and maps, e.g., type EnvVars []EnvVar, hold the
+		// same structs as their underlying type.
+		mt := underlyingType(m.Type)
+
+		if mt.Kind == types.Struct {
+			err = recurse(mt)
+		}
+
+		if (mt.Kind == types.Slice || mt.Kind == types.Pointer) && mt.Elem.Kind == types.Struct {
+			err = recurse(mt.Elem)
+		}
 
-		if m.Type.Kind == types.Struct {
-			recurse(m.Type)
+		// Slices of pointers, e.g., []*Probe.
+		if mt.Kind == types.Slice && mt.Elem.Kind == types.Pointer && mt.Elem.Elem.Kind == types.Struct {
+			err = recurse(mt.Elem.Elem)
 		}
 
-		if (m.Type.Kind == types.Slice || m.Type.Kind == types.Pointer) && m.Type.Elem.Kind == types.Struct {
-			recurse(m.Type.Elem)
+		// Map values, e.g., map[string]Backend or map[string][]Backend.
+		if mt.Kind == types.Map {
+			elem := types.Member{Name: m.Name, Type: underlyingType(mt.Elem)}
+			if elem.Type.Kind == types.Slice {
+				elem.Type = elem.Type.Elem
+			}
+			if elem.Type.Kind == types.Struct && !isTimeMember(elem) && !isDurationMember(elem) {
+				err = recurse(elem.Type)
+			}
+		}
+		if err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// The type under a named type, e.g., []EnvVar for type EnvVars []EnvVar.
+// Other types are returned as is.
+func underlyingType(t *types.Type) *types.Type {
+	if t.Kind == types.Alias {
+		return t.Underlying
+	}
+	return t
 }
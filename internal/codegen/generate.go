@@ -0,0 +1,364 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/imports"
+	"k8s.io/gengo/types"
+)
+
+// Options for a single run of the generator.
+//
+// The public starlarkgen package has its own copy of this type, so that
+// its fields are documented there. Keep the two in sync.
+type Options struct {
+	// The API package to read types from. May be a directory
+	// (starting with "." or "/") or an import path.
+	InputPackage string
+
+	// The name of the generated Go package.
+	// Defaults to the name of the input package.
+	OutputPackage string
+
+	// The name of the generated Plugin method that registers all the builtins.
+	// Defaults to DefaultRegisterFunc. Set this when generating several files
+	// into the same package. The generated helper types are prefixed with it,
+	// see HelperPrefix.
+	RegisterFunc string
+
+	// Nested structs that another file in the same package generates, e.g.,
+	// because top-level types in both files have fields of that type. The
+	// generated code uses their types, but doesn't declare them or register
+	// their builtins. Structs only reachable through them are left out too.
+	ExternalStructs []string
+
+	// The packages that provide the helpers the generated code calls.
+	// Defaults to DefaultRuntime. Missing import paths are filled in from
+	// DefaultRuntime.
+	Runtime Runtime
+
+	// If non-empty, only generate builtins for these top-level types.
+	// Otherwise, generates builtins for all types tagged with +tilt:starlark-gen=true.
+	Types []string
+
+	// Where argument names come from. Defaults to ArgNamesFromGo.
+	ArgNames ArgNameSource
+
+	// Lower camel case names for whole Go names, when the initialisms
+	// aren't enough, e.g., "UIButton" -> "uiButton".
+	Acronyms map[string]string
+
+	// Initialisms to convert as a single word, in addition to
+	// DefaultInitialisms, e.g., "IPs" so that PodIPs becomes pod_ips
+	// instead of pod_i_ps.
+	Initialisms []string
+
+	// Argument names to use instead of the default, keyed by the Go type whose
+	// members become the arguments and the member name, e.g.,
+	// "WidgetSpec.Name" -> "widget_name". For top-level objects, the type is
+	// the type of the Spec field.
+	//
+	// Generate fails if two arguments of a builtin have the same name,
+	// so renames are how to resolve collisions.
+	Renames map[string]string
+
+	// A JSON file with any of the naming options above, e.g.,
+	//
+	//	{
+	//	  "argNames": "json",
+	//	  "acronyms": {"UIButton": "uiButton"},
+	//	  "initialisms": ["IPs"],
+	//	  "renames": {"WidgetSpec.Name": "widget_name"}
+	//	}
+	//
+	// The options set here are added on top of the file's. May be empty.
+	NamingConfig string
+
+	// The name of the generated file, for positions in diagnostics.
+	// Defaults to DefaultOutputFileName.
+	FileName string
+
+	// Whether to type-check the generated code against the API package and
+	// the runtime, and report problems like undefined identifiers, missing
+	// imports, and bad conversions as SeverityError diagnostics.
+	//
+	// Imports are resolved from InputPackage's directory, so the module it's in
+	// must be able to import the runtime. If it can't, the code isn't checked,
+	// and there's a SeverityError diagnostic saying so.
+	TypeCheck bool
+
+	// A directory of templates that override the ones the generated code is
+	// rendered from. Each file is named after the template it replaces,
+	// e.g., attr.tmpl. See TemplateNames.
+	TemplateDir string
+
+	// Prints progress messages. May be nil.
+	Logf func(format string, args ...interface{})
+}
+
+func (o Options) logf(format string, args ...interface{}) {
+	if o.Logf != nil {
+		o.Logf(format, args...)
+	}
+}
+
+// How bad a diagnostic is.
+type Severity int
+
+const (
+	// The generated code is usable, but something looks wrong.
+	SeverityWarning Severity = iota
+
+	// The generated code won't compile.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// A problem with the generated code that didn't stop the generator.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+
+	// Where in the generated file the problem is, if known.
+	Pos token.Position
+
+	// The field of the API type that the code with the problem was generated
+	// for, if known, e.g., Step.Timeout.
+	Field string
+}
+
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if d.Pos.IsValid() {
+		msg = fmt.Sprintf("%s: %s", d.Pos, msg)
+	}
+	if d.Field != "" {
+		msg = fmt.Sprintf("%s (generated for %s)", msg, d.Field)
+	}
+	return fmt.Sprintf("%s: %s", d.Severity, msg)
+}
+
+// The result of a generator run.
+type Output struct {
+	// The generated Go file.
+	Source []byte
+
+	// Problems with the generated code.
+	//
+	// If the code couldn't be gofmt'd, Source contains the unformatted code,
+	// which is usually the easiest way to see what went wrong.
+	Diagnostics []Diagnostic
+}
+
+// Whether any of the diagnostics are errors.
+func (o Output) HasErrors() bool {
+	for _, d := range o.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Loads the input package and applies the type filter.
+func LoadTypes(opts Options) (*types.Package, []*types.Type, error) {
+	opts.logf("Loading types from %s", opts.InputPackage)
+	pkg, topTypes, err := LoadStarlarkGenTypes(opts.InputPackage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	topTypes, err = FilterTypes(topTypes, opts.Types)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.logf("Found %d top-level types in %s", len(topTypes), pkg.Path)
+	return pkg, topTypes, nil
+}
+
+// Loads the input package, and analyzes the types we'll generate builtins for.
+func LoadBindings(opts Options) (*Bindings, error) {
+	b, _, err := loadBindings(opts)
+	return b, err
+}
+
+// Like LoadBindings, but also returns the context the bindings were analyzed
+// with. The generated code must be rendered with the same context, so that
+// variables and imports agree on names.
+func loadBindings(opts Options) (*Bindings, *Context, error) {
+	pkg, topTypes, err := LoadTypes(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	naming, err := LoadNamingOptions(opts.NamingConfig, NamingOptions{
+		ArgNames:    opts.ArgNames,
+		Acronyms:    opts.Acronyms,
+		Initialisms: opts.Initialisms,
+		Renames:     opts.Renames,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := NewContext(pkg, opts.Runtime)
+	c.SetHelperPrefix(HelperPrefix(opts.RegisterFunc))
+	c.SetExternalStructs(opts.ExternalStructs)
+	b, err := Analyze(c, topTypes, naming)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, c, nil
+}
+
+// Runs the whole pipeline: load the types, generate the code, and format it.
+func Generate(opts Options) (Output, error) {
+	b, c, err := loadBindings(opts)
+	if err != nil {
+		return Output{}, err
+	}
+
+	outPkgName := opts.OutputPackage
+	if outPkgName == "" {
+		outPkgName = b.Pkg.Name
+	}
+
+	registerFunc := opts.RegisterFunc
+	if registerFunc == "" {
+		registerFunc = DefaultRegisterFunc
+	}
+
+	// Write the body first, so that we know what to import.
+	if opts.TemplateDir != "" {
+		opts.logf("Loading templates from %s", opts.TemplateDir)
+		templates, err := LoadTemplates(opts.TemplateDir)
+		if err != nil {
+			return Output{}, err
+		}
+		c.SetTemplates(templates)
+	}
+	buf := bytes.NewBuffer(nil)
+
+	err = WriteStarlarkRegistrationFunc(b, c, registerFunc, buf)
+	if err != nil {
+		return Output{}, err
+	}
+
+	for _, o := range b.Objects {
+		opts.logf("Generating builtin for %s", o.Type.Name.Name)
+		err := WriteStarlarkAPIObjectFunction(o, c, buf)
+		if err != nil {
+			return Output{}, err
+		}
+	}
+
+	for _, s := range b.Structs {
+		if s.External {
+			continue
+		}
+		opts.logf("Generating struct for %s", s.Type.Name.Name)
+		err = WriteStarlarkStructFunction(s, c, buf)
+		if err != nil {
+			return Output{}, err
+		}
+
+		err = WriteStarlarkStructListFunction(s, c, buf)
+		if err != nil {
+			return Output{}, err
+		}
+	}
+
+	for _, l := range b.ScalarLists {
+		err = WriteStarlarkScalarListFunction(l, c, buf)
+		if err != nil {
+			return Output{}, err
+		}
+	}
+
+	for _, m := range b.Maps {
+		err = WriteStarlarkMapFunction(m, c, buf)
+		if err != nil {
+			return Output{}, err
+		}
+	}
+
+	file := bytes.NewBuffer(nil)
+	err = WritePreamble(outPkgName, c, file)
+	if err != nil {
+		return Output{}, err
+	}
+	_, _ = file.Write(buf.Bytes())
+
+	// gofmt
+	out := Output{}
+	result, err := imports.Process("", file.Bytes(), nil)
+	if err != nil {
+		out.Source = file.Bytes()
+		out.Diagnostics = append(out.Diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("problem gofmting output: %v", err),
+		})
+	} else {
+		out.Source = result
+	}
+
+	if opts.TypeCheck {
+		opts.logf("Type-checking generated code")
+		fileName := opts.FileName
+		if fileName == "" {
+			fileName = DefaultOutputFileName
+		}
+		out.Diagnostics = append(out.Diagnostics, TypeCheck(b, fileName, out.Source, typeCheckDir(opts.InputPackage))...)
+	}
+	return out, nil
+}
+
+// The directory to resolve imports from when type-checking: the input
+// package's directory, or the current directory if the input is an import path.
+func typeCheckDir(inputDir string) string {
+	if strings.HasPrefix(inputDir, ".") || strings.HasPrefix(inputDir, "/") {
+		return filepath.Clean(inputDir)
+	}
+	return ""
+}
+
+// Restricts the top-level types to the given names.
+//
+// Returns an error if any name doesn't match a top-level type, so that typos
+// don't silently generate an empty file.
+func FilterTypes(topTypes []*types.Type, names []string) ([]*types.Type, error) {
+	if len(names) == 0 {
+		return topTypes, nil
+	}
+
+	byName := map[string]*types.Type{}
+	for _, t := range topTypes {
+		byName[t.Name.Name] = t
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		if byName[name] == nil {
+			return nil, fmt.Errorf("type %s not found (or not tagged with +tilt:starlark-gen=true)", name)
+		}
+		wanted[name] = true
+	}
+
+	result := []*types.Type{}
+	for _, t := range topTypes {
+		if wanted[t.Name.Name] {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	gotypes "go/types"
+)
+
+// Local variables that the templates declare, or parameters of the functions
+// they generate. Variables for fields can't use these names.
+//
+// Overridden templates should stick to these names for their own locals.
+var TemplateLocals = []string{
+	// Builtin parameters.
+	"p", "t", "fn", "args", "kwargs",
+
+	// Builtin locals.
+	"err", "obj", "labels", "annotations", "dict",
+
+	// Unpacker receivers, parameters, and locals.
+	"o", "v", "val", "key", "keyV", "item", "items", "ok", "ptr", "i",
+	"mapObj", "listObj", "starlarkObj",
+}
+
+// Picks Go identifiers for the local variables of a single generated function,
+// so that they never collide with keywords, predeclared identifiers, the
+// names packages are imported by, the function's own locals, or each other.
+type identAllocator struct {
+	// The prefix to try when a field's natural name is taken, e.g., spec.
+	prefix string
+	used   map[string]bool
+	names  *nameConverter
+}
+
+// The imports are the names the generated file refers to packages by.
+// See Context.ImportNames.
+func newIdentAllocator(prefix string, imports []string, names *nameConverter) *identAllocator {
+	a := &identAllocator{prefix: prefix, used: map[string]bool{}, names: names}
+	for _, name := range gotypes.Universe.Names() {
+		a.used[name] = true
+	}
+	for _, name := range TemplateLocals {
+		a.used[name] = true
+	}
+	for _, name := range imports {
+		a.used[name] = true
+	}
+	return a
+}
+
+// Allocates a variable name for a Go field, e.g., watchedPaths for WatchedPaths.
+//
+// If that's taken, tries the prefix, e.g., specArgs for Args. If that's
+// taken too, appends a number.
+func (a *identAllocator) allocate(goName string) string {
+	candidates := []string{
+		a.names.lowerCamel(goName),
+		a.prefix + a.names.camel(goName),
+	}
+	for _, c := range candidates {
+		if a.available(c) {
+			a.used[c] = true
+			return c
+		}
+	}
+
+	for n := 2; ; n++ {
+		c := fmt.Sprintf("%s%d", candidates[1], n)
+		if a.available(c) {
+			a.used[c] = true
+			return c
+		}
+	}
+}
+
+func (a *identAllocator) available(name string) bool {
+	return token.IsIdentifier(name) && !a.used[name]
+}
@@ -0,0 +1,46 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/gengo/types"
+)
+
+func TestIdentAllocator(t *testing.T) {
+	c := NewContext(&types.Package{Path: "example.com/api", Name: "api"}, DefaultRuntime)
+	a := newIdentAllocator("spec", c.ImportNames(), newNameConverter(NamingOptions{}))
+
+	assert.Equal(t, "watchedPaths", a.allocate("WatchedPaths"))
+
+	// Keywords, predeclared identifiers, packages, and template locals.
+	assert.Equal(t, "specType", a.allocate("Type"))
+	assert.Equal(t, "specString", a.allocate("String"))
+	assert.Equal(t, "specValue", a.allocate("Value"))
+	assert.Equal(t, "specApi", a.allocate("Api"))
+	assert.Equal(t, "specErr", a.allocate("Err"))
+
+	// Names already allocated in the same function, e.g., from
+	// two embedded structs with the same member.
+	assert.Equal(t, "url", a.allocate("Url"))
+	assert.Equal(t, "specUrl", a.allocate("Url"))
+	assert.Equal(t, "specUrl2", a.allocate("Url"))
+}
+
+func TestIdentAllocatorImportNames(t *testing.T) {
+	// The names the context actually imports packages by, not the
+	// packages' own names.
+	pkg := &types.Package{Path: "example.com/named_collections", Name: "named_collections"}
+	c := NewContext(pkg, Runtime{
+		StarkitPackage: "example.com/ourco/starkit",
+		ValuePackage:   "example.com/ourco/ignores",
+	})
+	assert.Equal(t, []string{
+		"fmt", "ignores", "math", "metav1", "namedcollections", "starkit", "starlark", "time",
+	}, c.ImportNames())
+
+	a := newIdentAllocator("spec", c.ImportNames(), newNameConverter(NamingOptions{}))
+	assert.Equal(t, "specIgnores", a.allocate("Ignores"))
+	assert.Equal(t, "specNamedcollections", a.allocate("Namedcollections"))
+	assert.Equal(t, "value", a.allocate("Value"))
+}
@@ -0,0 +1,301 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+	"text/template"
+
+	"golang.org/x/tools/go/packages"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+)
+
+const (
+	starlarkImportPath = "go.starlark.net/starlark"
+	metav1ImportPath   = "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Packages that the generated code refers to by a hard-coded name.
+// These go in the first import group. Everything else goes in the second.
+var fixedImports = []struct {
+	path string
+	name string
+}{
+	{starlarkImportPath, "starlark"},
+	{metav1ImportPath, "metav1"},
+}
+
+// Packages that goimports adds to the generated code, by their own names.
+var stdlibImports = []string{"fmt", "math", "time"}
+
+// The packages that provide the helpers the generated code calls at runtime.
+type Runtime struct {
+	// Import path of the package that provides UnpackArgs and Environment.
+	StarkitPackage string
+
+	// Import path of the package that provides StringList, StringStringMap,
+	// LocalPath, LocalPathList, and Duration.
+	//
+	// May be the same as StarkitPackage.
+	ValuePackage string
+}
+
+// The helpers in the Tilt codebase. These are internal packages, so code that
+// uses them only compiles inside Tilt.
+var DefaultRuntime = Runtime{
+	StarkitPackage: "github.com/tilt-dev/tilt/internal/tiltfile/starkit",
+	ValuePackage:   "github.com/tilt-dev/tilt/internal/tiltfile/value",
+}
+
+// The helpers in this repo's pkg/starlarkrt package, which any module can import.
+var StandaloneRuntime = Runtime{
+	StarkitPackage: "github.com/tilt-dev/tilt-starlark-codegen/pkg/starlarkrt",
+	ValuePackage:   "github.com/tilt-dev/tilt-starlark-codegen/pkg/starlarkrt",
+}
+
+// Looks up a runtime by the name used on the command line:
+// "tilt" for DefaultRuntime, or "standalone" for StandaloneRuntime.
+func RuntimeByName(name string) (Runtime, error) {
+	switch name {
+	case "tilt":
+		return DefaultRuntime, nil
+	case "standalone":
+		return StandaloneRuntime, nil
+	}
+	return Runtime{}, fmt.Errorf("runtime must be 'tilt' or 'standalone', got %q", name)
+}
+
+// Fills in any missing packages with the defaults.
+func (r Runtime) withDefaults() Runtime {
+	if r.StarkitPackage == "" {
+		r.StarkitPackage = DefaultRuntime.StarkitPackage
+	}
+	if r.ValuePackage == "" {
+		r.ValuePackage = DefaultRuntime.ValuePackage
+	}
+	return r
+}
+
+// Resolves a package directory to its import path, so that the types we load
+// have the same package path that generated code needs to import.
+//
+// Import paths are returned as-is.
+func resolveImportPath(dir string) (string, error) {
+	if !strings.HasPrefix(dir, ".") && !strings.HasPrefix(dir, "/") {
+		return dir, nil
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName}, dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving import path of %s: %v", dir, err)
+	}
+	if len(pkgs) != 1 || pkgs[0].PkgPath == "" {
+		return "", fmt.Errorf("resolving import path of %s: not a Go package", dir)
+	}
+	return pkgs[0].PkgPath, nil
+}
+
+// State shared by all the writers for a single generated file.
+type Context struct {
+	// The API package we're generating builtins for.
+	Pkg *types.Package
+
+	runtime     Runtime
+	imports     *namer.DefaultImportTracker
+	importPaths []string
+	namer       namer.Namer
+	templates   *Templates
+
+	// The templates with their functions bound to this context.
+	tmpl *template.Template
+
+	// The prefix of the generated helper types. See HelperPrefix.
+	helperPrefix string
+
+	// The nested structs that another file in the package generates.
+	externalStructs []string
+
+	// The first package we couldn't find an import name for. The import
+	// tracker can't return errors, so execute reports it instead.
+	importErr error
+}
+
+func NewContext(pkg *types.Package, runtime Runtime) *Context {
+	tracker := namer.NewDefaultImportTracker(types.Name{})
+	c := &Context{
+		Pkg:       pkg,
+		runtime:   runtime.withDefaults(),
+		imports:   &tracker,
+		templates: DefaultTemplates(),
+	}
+	tracker.IsInvalidType = func(*types.Type) bool { return false }
+	tracker.LocalName = c.localPackageName
+	tracker.PrintImport = c.printImport
+
+	// Claim names for the packages that the generated code always uses
+	// before any API types, so that API packages are the ones that get renamed
+	// on a collision.
+	for _, imp := range fixedImports {
+		c.addImport(imp.path)
+	}
+	c.addImport(c.runtime.StarkitPackage)
+	c.addImport(c.runtime.ValuePackage)
+
+	c.namer = namer.NewRawNamer("", c.imports)
+	return c
+}
+
+// Renders the generated code from the given templates
+// instead of the default ones.
+func (c *Context) SetTemplates(t *Templates) {
+	c.templates = t
+	c.tmpl = nil
+}
+
+// Prefixes the generated helper types, so that they don't collide with
+// the ones in other files in the same package. Analyze names the
+// helpers, so this must be called before it.
+func (c *Context) SetHelperPrefix(prefix string) {
+	c.helperPrefix = prefix
+}
+
+// Leaves the given nested structs, and the structs only reachable through
+// them, to another file in the same package. The generated code refers to
+// their types, but doesn't declare them or register their builtins.
+// Analyze decides which structs to generate, so this must be called before it.
+func (c *Context) SetExternalStructs(names []string) {
+	c.externalStructs = names
+}
+
+func (c *Context) addImport(path string) {
+	c.imports.AddType(&types.Type{Name: types.Name{Package: path}})
+}
+
+// The Go expression that refers to a named type from the generated package,
+// e.g., v1alpha1.FileWatch. Adds an import for the type's package.
+func (c *Context) TypeName(t *types.Type) string {
+	return c.namer.Name(t)
+}
+
+// The namer behind TypeName. Any type it names is added to the imports.
+func (c *Context) Namer() namer.Namer {
+	return c.namer
+}
+
+// Refers to a function or type in the runtime's starkit package,
+// e.g., starkit.UnpackArgs.
+func (c *Context) Starkit(name string) string {
+	return c.imports.LocalNameOf(c.runtime.StarkitPackage) + "." + name
+}
+
+// Refers to a function or type in the runtime's value package,
+// e.g., value.StringList.
+func (c *Context) Value(name string) string {
+	return c.imports.LocalNameOf(c.runtime.ValuePackage) + "." + name
+}
+
+// The names the generated file may refer to packages by: the standard library
+// packages goimports adds, the starlark, metav1, and runtime packages, the API
+// package, and the packages the API package imports. Claims a name for the API
+// package if it doesn't have one yet.
+//
+// Local variables in the generated code must avoid these, or they'd shadow the
+// package.
+func (c *Context) ImportNames() []string {
+	c.addImport(c.Pkg.Path)
+
+	names := append([]string{}, stdlibImports...)
+	for _, path := range c.importPaths {
+		names = append(names, c.imports.LocalNameOf(path))
+	}
+	for path := range c.Pkg.Imports {
+		names = append(names, c.declaredPackageName(path))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// The name of a package as declared in its source, if we loaded it.
+func (c *Context) declaredPackageName(path string) string {
+	if path == c.Pkg.Path {
+		return c.Pkg.Name
+	}
+	if imp, ok := c.Pkg.Imports[path]; ok && imp.Name != "" {
+		return imp.Name
+	}
+	parts := strings.Split(path, "/")
+	return parts[len(parts)-1]
+}
+
+// Picks the name we'll refer to a package by. Prefers the package's own name.
+// If that collides with a package we've already imported, prepends parent
+// directories until it's unique, e.g., corev1alpha1.
+//
+// If every candidate is taken, records an error and returns the last one.
+func (c *Context) localPackageName(name types.Name) string {
+	path := name.Package
+	c.importPaths = append(c.importPaths, path)
+
+	for _, imp := range fixedImports {
+		if imp.path == path {
+			return imp.name
+		}
+	}
+
+	candidates := []string{c.declaredPackageName(path)}
+	dirs := strings.Split(path, "/")
+	for n := len(dirs) - 2; n >= 0; n-- {
+		candidates = append(candidates, strings.Join(dirs[n:], ""))
+	}
+
+	candidate := ""
+	for _, candidate = range candidates {
+		candidate = sanitizePackageName(candidate)
+		_, found := c.imports.PathOf(candidate)
+		if !found && !token.Lookup(candidate).IsKeyword() {
+			return candidate
+		}
+	}
+	if c.importErr == nil {
+		c.importErr = fmt.Errorf("can't find an import name for %s", path)
+	}
+	return candidate
+}
+
+func sanitizePackageName(name string) string {
+	return strings.NewReplacer("_", "", ".", "", "-", "").Replace(name)
+}
+
+func isFixedImport(path string) bool {
+	for _, imp := range fixedImports {
+		if imp.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Context) printImport(path, name string) string {
+	if name == c.declaredPackageName(path) {
+		return fmt.Sprintf("%q", path)
+	}
+	return fmt.Sprintf("%s %q", name, path)
+}
+
+// The import lines for all the packages we've referenced, in two groups.
+func (c *Context) ImportGroups() (first []string, second []string) {
+	paths := append([]string{}, c.importPaths...)
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		line := c.printImport(path, c.imports.LocalNameOf(path))
+		if isFixedImport(path) {
+			first = append(first, line)
+		} else {
+			second = append(second, line)
+		}
+	}
+	return first, second
+}
@@ -0,0 +1,59 @@
+package codegen
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/gengo/types"
+)
+
+func namedType(pkg, name string) *types.Type {
+	return &types.Type{Name: types.Name{Package: pkg, Name: name}, Kind: types.Struct}
+}
+
+func TestImportNames(t *testing.T) {
+	pkg := &types.Package{Path: "example.com/ourco/api/v1beta1", Name: "v1beta1"}
+	c := NewContext(pkg, DefaultRuntime)
+
+	assert.Equal(t, "v1beta1.Widget", c.TypeName(namedType(pkg.Path, "Widget")))
+
+	// Collisions prepend parent directories.
+	assert.Equal(t, "corev1beta1.Pod", c.TypeName(namedType("example.com/core/v1beta1", "Pod")))
+
+	// Names are sanitized, and the runtime's names are taken first.
+	assert.Equal(t, "mylib.Thing", c.TypeName(namedType("example.com/my-lib", "Thing")))
+	assert.Equal(t, "othervalue.Thing", c.TypeName(namedType("example.com/other/value", "Thing")))
+
+	first, second := c.ImportGroups()
+	assert.Equal(t, []string{
+		`"go.starlark.net/starlark"`,
+		`metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"`,
+	}, first)
+	assert.Equal(t, []string{
+		`corev1beta1 "example.com/core/v1beta1"`,
+		`mylib "example.com/my-lib"`,
+		`othervalue "example.com/other/value"`,
+		`"example.com/ourco/api/v1beta1"`,
+		`"github.com/tilt-dev/tilt/internal/tiltfile/starkit"`,
+		`"github.com/tilt-dev/tilt/internal/tiltfile/value"`,
+	}, second)
+}
+
+func TestImportNameNotFound(t *testing.T) {
+	pkg := &types.Package{Path: "example.com/abc", Name: "abc"}
+	c := NewContext(pkg, DefaultRuntime)
+
+	// Every candidate for the second package is taken by the first.
+	out := bytes.NewBuffer(nil)
+	err := WriteStarlarkAPIObjectFunction(&Object{
+		Builtin: Builtin{Type: namedType(pkg.Path, "Widget")},
+	}, c, out)
+	require.NoError(t, err)
+
+	err = WriteStarlarkAPIObjectFunction(&Object{
+		Builtin: Builtin{Type: namedType("abc", "Widget")},
+	}, c, out)
+	assert.EqualError(t, err, "can't find an import name for abc")
+}
@@ -0,0 +1,762 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/gengo/types"
+)
+
+// The Starlark bindings for an API package.
+//
+// Analyze computes this once from the Go types. The emitters render it,
+// so that all the decisions about names and conversions are made in one place.
+type Bindings struct {
+	// The API package.
+	Pkg *types.Package
+
+	// Builtins for the top-level API objects, sorted by Go type name.
+	Objects []*Object
+
+	// Builtins for the structs nested in the objects, sorted by Go type name.
+	Structs []*Struct
+
+	// List types for the slices of builtins other than strings, sorted by
+	// element type name.
+	ScalarLists []*ScalarList
+
+	// Dict types for the maps with values other than strings, sorted by
+	// type name.
+	Maps []*Map
+}
+
+// The parts common to every builtin.
+type Builtin struct {
+	// The Go type the builtin constructs.
+	Type *types.Type
+
+	// The name the builtin is registered under, e.g., v1alpha1.file_watch.
+	Name string
+
+	// The name of the generated Plugin method, e.g., fileWatch.
+	FuncName string
+
+	// The arguments of the builtin.
+	Fields []*Field
+}
+
+// A builtin that constructs a top-level API object and registers it.
+//
+// Besides its fields, it always takes name, labels, and annotations.
+type Object struct {
+	Builtin
+
+	// The type of the Spec field. Nil for objects that only have
+	// a Data field, like ConfigMap.
+	SpecType *types.Type
+}
+
+// A struct type nested in an object.
+//
+// Each struct gets a builtin that constructs it, a Starlark type that wraps it,
+// and a Starlark list type. Builtins can take the struct as a dict as well.
+type Struct struct {
+	Builtin
+
+	// The name of the generated Starlark type, e.g., IgnoreDef.
+	StarlarkType string
+
+	// The name of the generated Starlark list type, e.g., IgnoreDefList.
+	ListType string
+
+	// Whether a field is a slice of pointers to the struct, e.g., []*IgnoreDef,
+	// so the list type needs a Pointers method.
+	PointerList bool
+
+	// Whether another file in the same package generates the struct, so this
+	// file refers to its types, but doesn't declare them or register its
+	// builtin. External structs have no fields.
+	External bool
+}
+
+// A generated list type for a slice of builtins, e.g., Int32List for []int32.
+//
+// It accepts a Starlark list or tuple, and converts each element the same way
+// as a struct attribute of the element type.
+type ScalarList struct {
+	// The name of the generated type, e.g., Int32List.
+	ListType string
+
+	// How each element converts, e.g., to an int32.
+	Elem *Converter
+}
+
+// A generated dict type for a map from strings to values other than strings,
+// e.g., BackendMap for map[string]Backend.
+//
+// It accepts a Starlark dict with string keys, and converts each value the
+// same way as a struct attribute of the value type.
+type Map struct {
+	// The name of the generated type, e.g., BackendMap.
+	MapType string
+
+	// How each value converts, e.g., from a dict or a Backend.
+	Elem *Converter
+}
+
+// A Starlark argument, and the Go field it's copied into.
+type Field struct {
+	// The Go struct member.
+	Member types.Member
+
+	// The keyword argument name, which is also the dict key for structs,
+	// e.g., watched_paths.
+	Name string
+
+	// The path to the Go field from the object being built,
+	// e.g., Spec.WatchedPaths.
+	GoName string
+
+	// The name of the local variable the argument is unpacked into,
+	// if it needs one.
+	Var string
+
+	// For objects, the local variable the argument is unpacked into, or nil
+	// if UnpackArgs writes it into the object directly. Struct builtins
+	// always unpack into a starlark.Value named Var.
+	Local *LocalVar
+
+	// How the Starlark value converts into the Go field.
+	Converter *Converter
+}
+
+// A local variable that an object's argument is unpacked into, before it's
+// converted and copied into the object.
+type LocalVar struct {
+	// The Go type of the variable, e.g., value.StringList.
+	Type string
+
+	// The Go expression the variable starts as, if any, e.g., IgnoreDef{t: t}.
+	// Variables with one are unpackers that hold their result in Value.
+	Init string
+
+	// Whether the variable holds the Starlark value, which the "scalar"
+	// template converts after UnpackArgs.
+	Scalar bool
+}
+
+// The kinds of Starlark values we know how to convert.
+type ConverterKind int
+
+const (
+	// A string, bool, or number, or a named type over one of them.
+	ScalarConverter ConverterKind = iota
+
+	// A string tagged +tilt:local-path, resolved against the Tiltfile's directory.
+	LocalPathConverter
+
+	// A duration string, e.g., "5s", into a metav1.Duration.
+	DurationConverter
+
+	// A struct, or a pointer to a struct, from a dict or its builtin.
+	StructConverter
+
+	// A []string from a list of strings.
+	StringListConverter
+
+	// A []string tagged +tilt:local-path, from a path or a list of paths.
+	LocalPathListConverter
+
+	// A slice of bools or numbers, from a list or tuple.
+	ScalarListConverter
+
+	// A list of structs.
+	StructListConverter
+
+	// A map[string]string from a dict.
+	StringMapConverter
+
+	// A map from strings to other values, from a dict.
+	MapConverter
+)
+
+var converterKindNames = map[ConverterKind]string{
+	ScalarConverter:        "Scalar",
+	LocalPathConverter:     "LocalPath",
+	DurationConverter:      "Duration",
+	StructConverter:        "Struct",
+	StringListConverter:    "StringList",
+	LocalPathListConverter: "LocalPathList",
+	ScalarListConverter:    "ScalarList",
+	StructListConverter:    "StructList",
+	StringMapConverter:     "StringMap",
+	MapConverter:           "Map",
+}
+
+// The name of the kind without the Converter suffix, e.g., LocalPath.
+// Templates compare against these names.
+func (k ConverterKind) String() string {
+	name, ok := converterKindNames[k]
+	if !ok {
+		return fmt.Sprintf("ConverterKind(%d)", int(k))
+	}
+	return name
+}
+
+// How a Starlark value converts into a Go field.
+type Converter struct {
+	Kind ConverterKind
+
+	// The type of the Go field.
+	Type *types.Type
+
+	// For scalars and local paths, the builtin type under any pointer or
+	// named type, e.g., string for a *FileWatchStrategy.
+	Builtin *types.Type
+
+	// For structs and lists of structs, the struct.
+	Struct *Struct
+
+	// For lists of scalars, the generated list type.
+	List *ScalarList
+
+	// For maps with values other than strings, the generated dict type.
+	Map *Map
+}
+
+// Whether the Go field is a pointer to the value.
+func (c *Converter) Pointer() bool {
+	return c.Type.Kind == types.Pointer
+}
+
+// Whether the Go field is a slice of pointers, e.g., []*Probe.
+func (c *Converter) ElemPointer() bool {
+	t := underlyingType(c.Type)
+	return t.Kind == types.Slice && t.Elem.Kind == types.Pointer
+}
+
+// The named type the value needs to be converted to, if any,
+// e.g., FileWatchStrategy for a field of type *FileWatchStrategy.
+func (c *Converter) Named() *types.Type {
+	t := c.Type
+	if t.Kind == types.Pointer {
+		t = t.Elem
+	}
+	if t.Kind == types.Alias {
+		return t
+	}
+	return nil
+}
+
+// Whether the value is a Go integer of any width, e.g., int64 or uint16.
+func (c *Converter) Int() bool {
+	return c.Builtin != nil && intTypes[c.Builtin.Name.Name]
+}
+
+// Whether the value is a Go float, i.e., float32 or float64.
+func (c *Converter) Float() bool {
+	return c.Builtin != nil && (isBuiltin(c.Builtin, "float32") || isBuiltin(c.Builtin, "float64"))
+}
+
+// The Go integer types that starlark.AsInt can convert to, with a range check.
+var intTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+}
+
+// All the builtins, objects first. External structs are left out,
+// since another file registers them.
+func (b *Bindings) Builtins() []*Builtin {
+	result := []*Builtin{}
+	for _, o := range b.Objects {
+		result = append(result, &o.Builtin)
+	}
+	for _, s := range b.Structs {
+		if !s.External {
+			result = append(result, &s.Builtin)
+		}
+	}
+	return result
+}
+
+// Computes the bindings for the given top-level types and the structs
+// nested in them.
+//
+// The context is the one the bindings will be rendered with. Local variables
+// avoid the names it imports packages by, so they can't shadow them.
+//
+// Returns an error if two arguments of a builtin would have the same name.
+func Analyze(c *Context, topTypes []*types.Type, naming NamingOptions) (*Bindings, error) {
+	err := naming.validate()
+	if err != nil {
+		return nil, err
+	}
+	names := newNameConverter(naming)
+	kwargs := newKwargNamer(naming, names)
+
+	external := map[string]bool{}
+	for _, name := range c.externalStructs {
+		external[name] = true
+	}
+	memberTypes, err := FindStructMembers(topTypes, naming, external)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := c.Pkg
+	imports := c.ImportNames()
+	b := &Bindings{Pkg: pkg}
+
+	// Create all the structs first, so that fields can refer to them.
+	structs := map[string]*Struct{}
+	for _, t := range memberTypes {
+		s := &Struct{
+			Builtin:      newBuiltin(t, pkg, names),
+			StarlarkType: t.Name.Name,
+			ListType:     fmt.Sprintf("%sList", t.Name.Name),
+			External:     external[t.Name.Name],
+		}
+		structs[t.Name.Name] = s
+		b.Structs = append(b.Structs, s)
+	}
+	for _, name := range c.externalStructs {
+		if structs[name] == nil {
+			return nil, fmt.Errorf("external struct %s isn't nested in the generated types", name)
+		}
+	}
+
+	collections := newCollectionTypes(c.helperPrefix, names)
+	for _, t := range topTypes {
+		o, err := analyzeObject(t, c, imports, structs, collections, names, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		b.Objects = append(b.Objects, o)
+	}
+
+	for _, s := range b.Structs {
+		if s.External {
+			continue
+		}
+		vars := newIdentAllocator("attr", imports, names)
+		args := kwargs.newSet(s.Type.Name.Name, nil)
+		for _, m := range flattenEmbedded(s.Type.Members) {
+			// Skip Time and MicroTime for now.
+			if isTimeMember(m) {
+				continue
+			}
+			skip, err := naming.skips(m)
+			if err != nil {
+				return nil, fmt.Errorf("generating %s unpacker: %v", s.Type.Name.Name, err)
+			}
+			if skip {
+				continue
+			}
+
+			conv, err := analyzeMember(m, structs, collections)
+			if err == nil && conv.Builtin != nil && isBuiltin(conv.Builtin, "byte") {
+				// gengo represents both int8 and uint8 as byte, so we can't
+				// tell which one to convert to.
+				err = fmt.Errorf("Unable to unpack attribute %s: int8 and uint8 are not supported", m.Name)
+			} else if err == nil && !supportedInStruct(conv) {
+				err = fmt.Errorf("Unable to unpack attribute %s type %s", m.Name, m.Type)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("generating %s unpacker: %v", s.Type.Name.Name, err)
+			}
+
+			name, err := args.add(m)
+			if err != nil {
+				return nil, fmt.Errorf("generating %s unpacker: %v", s.Type.Name.Name, err)
+			}
+
+			s.Fields = append(s.Fields, &Field{
+				Member:    m,
+				Name:      name,
+				GoName:    m.Name,
+				Var:       vars.allocate(m.Name),
+				Converter: conv,
+			})
+		}
+	}
+
+	err = kwargs.checkUnused(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = collections.addTo(b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func newBuiltin(t *types.Type, pkg *types.Package, names *nameConverter) Builtin {
+	return Builtin{
+		Type:     t,
+		Name:     fmt.Sprintf("%s.%s", pkg.Name, names.snake(t.Name.Name)),
+		FuncName: names.lowerCamel(t.Name.Name),
+	}
+}
+
+func analyzeObject(t *types.Type, c *Context, imports []string, structs map[string]*Struct, collections *collectionTypes, names *nameConverter, kwargs *kwargNamer) (*Object, error) {
+	o := &Object{Builtin: newBuiltin(t, c.Pkg, names)}
+
+	spec := getSpecMemberType(t)
+	data := getDataMember(t)
+	var members []types.Member
+	fieldPrefix := ""
+	memberOf := t
+	if spec != nil {
+		o.SpecType = spec
+		members = spec.Members
+		fieldPrefix = "Spec."
+		memberOf = spec
+	} else if data != nil {
+		members = []types.Member{*data}
+	} else {
+		return nil, fmt.Errorf("type has no spec or data field: %s", t.Name.Name)
+	}
+
+	vars := newIdentAllocator("spec", imports, names)
+	args := kwargs.newSet(memberOf.Name.Name, objectMetaArgs)
+	for _, m := range members {
+		if isTimeMember(m) {
+			continue
+		}
+		skip, err := kwargs.opts.skips(m)
+		if err != nil {
+			return nil, fmt.Errorf("generating type %s: %v", t.Name.Name, err)
+		}
+		if skip {
+			continue
+		}
+
+		conv, err := analyzeMember(m, structs, collections)
+		if err == nil && !supportedInObject(conv) {
+			err = fmt.Errorf("Cannot unpack member %s", m.Name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("generating type %s: %v", t.Name.Name, err)
+		}
+
+		name, err := args.add(m)
+		if err != nil {
+			return nil, fmt.Errorf("generating type %s: %v", t.Name.Name, err)
+		}
+
+		f := &Field{
+			Member:    m,
+			Name:      name,
+			GoName:    fieldPrefix + m.Name,
+			Local:     objectLocal(conv, c),
+			Converter: conv,
+		}
+		if f.Local != nil {
+			f.Var = vars.allocate(m.Name)
+		}
+		o.Fields = append(o.Fields, f)
+	}
+	return o, nil
+}
+
+// The local variable that an object argument is unpacked into, or nil if
+// UnpackArgs can write it into the object directly.
+func objectLocal(conv *Converter, c *Context) *LocalVar {
+	switch conv.Kind {
+	case ScalarConverter:
+		// UnpackArgs doesn't accept ints for floats, so floats are
+		// converted like struct attributes instead.
+		if conv.Float() {
+			return &LocalVar{Type: "starlark.Value", Scalar: true}
+		}
+		// Named types are unpacked as their builtin type, then converted.
+		if conv.Named() == nil {
+			return nil
+		}
+		return &LocalVar{Type: conv.Builtin.Name.Name}
+
+	case LocalPathConverter:
+		return &LocalVar{Type: c.Value("LocalPath"), Init: c.Value("NewLocalPathUnpacker") + "(t)"}
+
+	case DurationConverter:
+		return &LocalVar{Type: c.Value("Duration")}
+
+	case StructConverter:
+		return &LocalVar{Type: conv.Struct.StarlarkType, Init: conv.Struct.StarlarkType + "{t: t}"}
+
+	case StringListConverter:
+		return &LocalVar{Type: c.Value("StringList")}
+
+	case ScalarListConverter:
+		return &LocalVar{Type: conv.List.ListType}
+
+	case LocalPathListConverter:
+		return &LocalVar{Type: c.Value("LocalPathList"), Init: c.Value("NewLocalPathListUnpacker") + "(t)"}
+
+	case StructListConverter:
+		return &LocalVar{Type: conv.Struct.ListType, Init: conv.Struct.ListType + "{t: t}"}
+
+	case StringMapConverter:
+		return &LocalVar{Type: c.Value("StringStringMap")}
+
+	case MapConverter:
+		return &LocalVar{Type: conv.Map.MapType, Init: conv.Map.MapType + "{t: t}"}
+	}
+	panic(fmt.Sprintf("unknown converter kind: %v", conv.Kind))
+}
+
+// Decides how to convert a Starlark value into a member.
+func analyzeMember(m types.Member, structs map[string]*Struct, collections *collectionTypes) (*Converter, error) {
+	isLocalPath, err := types.ExtractSingleBoolCommentTag("+", "tilt:local-path", false, m.CommentLines)
+	if err != nil {
+		return nil, fmt.Errorf("parsing tags in %s: %v", m.Name, err)
+	}
+
+	conv := &Converter{Type: m.Type}
+	t := m.Type
+	if t.Kind == types.Pointer {
+		t = t.Elem
+	}
+
+	if isDurationMember(m) {
+		conv.Kind = DurationConverter
+		return conv, nil
+	}
+
+	// Named types convert like their underlying type, and the value is
+	// converted back, e.g., type EnvVars []EnvVar like a []EnvVar.
+	t = underlyingType(t)
+	if t.Kind == types.Builtin {
+		conv.Builtin = t
+		conv.Kind = ScalarConverter
+		if isLocalPath && t.Name.Name == "string" {
+			conv.Kind = LocalPathConverter
+		}
+		return conv, nil
+	}
+
+	switch t.Kind {
+	case types.Struct:
+		s, ok := structs[t.Name.Name]
+		if ok {
+			conv.Kind = StructConverter
+			conv.Struct = s
+			return conv, nil
+		}
+
+	case types.Slice:
+		if conv.Pointer() {
+			break
+		}
+		if isBuiltin(t.Elem, "string") {
+			conv.Kind = StringListConverter
+			if isLocalPath {
+				conv.Kind = LocalPathListConverter
+			}
+			return conv, nil
+		}
+		elem := t.Elem
+		if elem.Kind == types.Pointer {
+			elem = elem.Elem
+		}
+		s, ok := structs[elem.Name.Name]
+		if ok && elem.Kind == types.Struct {
+			conv.Kind = StructListConverter
+			conv.Struct = s
+			if conv.ElemPointer() {
+				s.PointerList = true
+			}
+			return conv, nil
+		}
+		if isNumberOrBool(t.Elem) {
+			conv.Kind = ScalarListConverter
+			conv.List = collections.list(t.Elem)
+			return conv, nil
+		}
+
+	case types.Map:
+		if conv.Pointer() {
+			break
+		}
+		if !isBuiltin(t.Key, "string") {
+			return nil, fmt.Errorf("Cannot unpack member %s: map keys must be strings, got %s", m.Name, t.Key)
+		}
+		if isBuiltin(t.Elem, "string") {
+			conv.Kind = StringMapConverter
+			return conv, nil
+		}
+
+		elem, err := analyzeMember(types.Member{Name: m.Name, Type: t.Elem}, structs, collections)
+		if err != nil || !supportedInMap(elem) {
+			return nil, fmt.Errorf("Cannot unpack member %s: unsupported map value type %s", m.Name, t.Elem)
+		}
+		conv.Kind = MapConverter
+		conv.Map, err = collections.dict(elem)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot unpack member %s: %v", m.Name, err)
+		}
+		return conv, nil
+	}
+	return nil, fmt.Errorf("Cannot unpack member %s", m.Name)
+}
+
+// Map values are converted by the generated dict types, which handle
+// the same values as struct attributes, except pointers, slices of
+// pointers, local paths, durations, and nested maps.
+func supportedInMap(conv *Converter) bool {
+	switch conv.Kind {
+	case ScalarConverter:
+		return !conv.Pointer() && supportedInStruct(conv) && !isBuiltin(conv.Builtin, "byte")
+	case StructConverter:
+		return !conv.Pointer()
+	case StringListConverter, ScalarListConverter:
+		return true
+	case StructListConverter:
+		return !conv.ElemPointer()
+	}
+	return false
+}
+
+func isBuiltin(t *types.Type, name string) bool {
+	return t.Kind == types.Builtin && t.Name.Name == name
+}
+
+func isStringOrBool(t *types.Type) bool {
+	return isBuiltin(t, "string") || isBuiltin(t, "bool")
+}
+
+// Whether a type is a bool, or an integer or float of a width we can
+// tell apart. gengo represents both int8 and uint8 as byte.
+func isNumberOrBool(t *types.Type) bool {
+	return t.Kind == types.Builtin && (t.Name.Name == "bool" || intTypes[t.Name.Name] ||
+		t.Name.Name == "float32" || t.Name.Name == "float64")
+}
+
+// Top-level arguments are unpacked with UnpackArgs, which handles
+// strings, bools, and integers, but not pointers to them. Floats are
+// converted after UnpackArgs, like struct attributes, so they take ints.
+func supportedInObject(conv *Converter) bool {
+	switch conv.Kind {
+	case ScalarConverter:
+		return !conv.Pointer() && (isStringOrBool(conv.Builtin) || conv.Int() || conv.Float())
+	case LocalPathConverter, DurationConverter:
+		return !conv.Pointer()
+	}
+	return true
+}
+
+// Struct attributes are unpacked by the generated code, which handles
+// strings, bools, and numbers of any width.
+func supportedInStruct(conv *Converter) bool {
+	switch conv.Kind {
+	case ScalarConverter:
+		return isStringOrBool(conv.Builtin) || conv.Int() || conv.Float()
+	case DurationConverter:
+		return !conv.Pointer()
+	}
+	return true
+}
+
+// The generated list and dict types that fields refer to.
+type collectionTypes struct {
+	prefix string
+	names  *nameConverter
+
+	// By element type name.
+	lists map[string]*ScalarList
+
+	// By generated type name.
+	maps map[string]*Map
+}
+
+func newCollectionTypes(prefix string, names *nameConverter) *collectionTypes {
+	return &collectionTypes{
+		prefix: prefix,
+		names:  names,
+		lists:  map[string]*ScalarList{},
+		maps:   map[string]*Map{},
+	}
+}
+
+// The list type for an element type, created the first time it's needed.
+// It's named after the element type, e.g., Int32List.
+func (c *collectionTypes) list(elem *types.Type) *ScalarList {
+	list, ok := c.lists[elem.Name.Name]
+	if !ok {
+		list = &ScalarList{
+			ListType: c.prefix + c.names.camel(elem.Name.Name) + "List",
+			Elem:     &Converter{Kind: ScalarConverter, Type: elem, Builtin: elem},
+		}
+		c.lists[elem.Name.Name] = list
+	}
+	return list
+}
+
+// The dict type for a value type, created the first time it's needed.
+// It's named after the value type, e.g., Int64Map, BackendMap, or
+// StringListMap.
+//
+// Returns an error if two value types would have the same name, e.g.,
+// types with the same name in different packages.
+func (c *collectionTypes) dict(elem *Converter) (*Map, error) {
+	t := elem.Type
+	suffix := "Map"
+	if t.Kind == types.Slice {
+		t = t.Elem
+		suffix = "ListMap"
+	}
+	name := c.prefix + c.names.camel(t.Name.Name) + suffix
+
+	m, ok := c.maps[name]
+	if !ok {
+		m = &Map{MapType: name, Elem: elem}
+		c.maps[name] = m
+	} else if m.Elem.Type != elem.Type {
+		return nil, fmt.Errorf("the generated type %s is needed for both %s and %s", name, m.Elem.Type, elem.Type)
+	}
+	return m, nil
+}
+
+// Adds all the list and dict types to the bindings, sorted.
+//
+// Returns an error if one has the same name as another generated type,
+// e.g., if the API package has a struct named Int32 or BackendMap.
+func (c *collectionTypes) addTo(b *Bindings) error {
+	taken := map[string]string{}
+	for _, s := range b.Structs {
+		taken[s.StarlarkType] = s.Type.Name.Name
+		taken[s.ListType] = s.Type.Name.Name
+	}
+	claim := func(name string, t *types.Type) error {
+		if owner, ok := taken[name]; ok {
+			return fmt.Errorf("the generated type %s for %s collides with the generated types for %s", name, t, owner)
+		}
+		taken[name] = t.String()
+		return nil
+	}
+
+	for _, list := range c.lists {
+		err := claim(list.ListType, list.Elem.Type)
+		if err != nil {
+			return err
+		}
+		b.ScalarLists = append(b.ScalarLists, list)
+	}
+	sort.Slice(b.ScalarLists, func(i, j int) bool {
+		return b.ScalarLists[i].Elem.Builtin.Name.Name < b.ScalarLists[j].Elem.Builtin.Name.Name
+	})
+
+	for _, m := range c.maps {
+		err := claim(m.MapType, m.Elem.Type)
+		if err != nil {
+			return err
+		}
+		b.Maps = append(b.Maps, m)
+	}
+	sort.Slice(b.Maps, func(i, j int) bool {
+		return b.Maps[i].MapType < b.Maps[j].MapType
+	})
+	return nil
+}
@@ -0,0 +1,413 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/gengo/types"
+)
+
+func analyzeTestdata(t *testing.T, name string) *Bindings {
+	pkg, topTypes, err := LoadStarlarkGenTypes("../../test/testdata/" + name)
+	require.NoError(t, err)
+	b, err := Analyze(NewContext(pkg, DefaultRuntime), topTypes, NamingOptions{})
+	require.NoError(t, err)
+	return b
+}
+
+type fieldSummary struct {
+	Name   string
+	GoName string
+	Kind   ConverterKind
+}
+
+func summarize(fields []*Field) []fieldSummary {
+	result := []fieldSummary{}
+	for _, f := range fields {
+		result = append(result, fieldSummary{f.Name, f.GoName, f.Converter.Kind})
+	}
+	return result
+}
+
+func TestAnalyzeObject(t *testing.T) {
+	b := analyzeTestdata(t, "nested")
+	require.Len(t, b.Objects, 1)
+
+	o := b.Objects[0]
+	assert.Equal(t, "nested.build", o.Name)
+	assert.Equal(t, "build", o.FuncName)
+	assert.Equal(t, "BuildSpec", o.SpecType.Name.Name)
+	assert.Equal(t, []fieldSummary{
+		{"context", "Spec.Context", LocalPathConverter},
+		{"steps", "Spec.Steps", StructListConverter},
+		{"cache", "Spec.Cache", StructConverter},
+	}, summarize(o.Fields))
+
+	cache := o.Fields[2].Converter
+	assert.True(t, cache.Pointer())
+	assert.Equal(t, "Cache", cache.Struct.StarlarkType)
+}
+
+func TestAnalyzeStructs(t *testing.T) {
+	b := analyzeTestdata(t, "nested")
+
+	names := []string{}
+	structs := map[string]*Struct{}
+	for _, s := range b.Structs {
+		names = append(names, s.Name)
+		structs[s.StarlarkType] = s
+	}
+	assert.Equal(t, []string{"nested.cache", "nested.cache_target", "nested.retry", "nested.step"}, names)
+
+	// Embedded structs are flattened, and time fields are skipped.
+	assert.Equal(t, []fieldSummary{
+		{"key", "Key", ScalarConverter},
+		{"paths", "Paths", LocalPathListConverter},
+		{"disabled", "Disabled", ScalarConverter},
+	}, summarize(structs["Cache"].Fields))
+	assert.Equal(t, []fieldSummary{
+		{"command", "Command", StringListConverter},
+		{"dir", "Dir", LocalPathConverter},
+		{"inputs", "Inputs", LocalPathListConverter},
+		{"env", "Env", StringMapConverter},
+		{"timeout", "Timeout", DurationConverter},
+		{"retry", "Retry", StructConverter},
+	}, summarize(structs["Step"].Fields))
+	assert.Equal(t, "StepList", structs["Step"].ListType)
+
+	disabled := structs["Cache"].Fields[2].Converter
+	assert.Equal(t, "Toggle", disabled.Named().Name.Name)
+	assert.Equal(t, "bool", disabled.Builtin.Name.Name)
+
+	attempts := structs["Retry"].Fields[0].Converter
+	assert.True(t, attempts.Pointer())
+	assert.Nil(t, attempts.Named())
+	assert.Equal(t, "int32", attempts.Builtin.Name.Name)
+}
+
+func TestAnalyzeObjectLocals(t *testing.T) {
+	b := analyzeTestdata(t, "numbers")
+
+	locals := map[string]*LocalVar{}
+	vars := map[string]string{}
+	for _, f := range b.Objects[0].Fields {
+		locals[f.Name] = f.Local
+		vars[f.Name] = f.Var
+	}
+
+	// UnpackArgs writes ints directly into the object.
+	assert.Nil(t, locals["timeout_seconds"])
+	assert.Nil(t, locals["burst"])
+	assert.Equal(t, "", vars["burst"])
+
+	// Floats and named floats are unpacked as Starlark values, so they take ints.
+	assert.Equal(t, &LocalVar{Type: "starlark.Value", Scalar: true}, locals["ratio"])
+	assert.Equal(t, &LocalVar{Type: "starlark.Value", Scalar: true}, locals["share"])
+	assert.Equal(t, "ratio", vars["ratio"])
+
+	// Structs are unpacked by their Starlark type.
+	assert.Equal(t, &LocalVar{Type: "Window", Init: "Window{t: t}"}, locals["window"])
+
+	b = analyzeTestdata(t, "nested")
+	assert.Equal(t, &LocalVar{Type: "value.LocalPath", Init: "value.NewLocalPathUnpacker(t)"}, b.Objects[0].Fields[0].Local)
+	assert.Equal(t, &LocalVar{Type: "StepList", Init: "StepList{t: t}"}, b.Objects[0].Fields[1].Local)
+
+	// Struct builtins always unpack into a starlark.Value.
+	assert.Nil(t, b.Structs[0].Fields[0].Local)
+	assert.NotEqual(t, "", b.Structs[0].Fields[0].Var)
+}
+
+func TestAnalyzeScalarLists(t *testing.T) {
+	b := analyzeTestdata(t, "scalar_lists")
+
+	// One list type per element type, shared by all the fields.
+	names := []string{}
+	for _, l := range b.ScalarLists {
+		names = append(names, l.ListType)
+	}
+	assert.Equal(t, []string{"BoolList", "Float64List", "Int32List", "Uint16List"}, names)
+
+	ports := b.Objects[0].Fields[0].Converter
+	assert.Equal(t, ScalarListConverter, ports.Kind)
+	assert.Same(t, b.ScalarLists[2], ports.List)
+	assert.Same(t, ports.List, b.Structs[0].Fields[1].Converter.List)
+	assert.True(t, ports.List.Elem.Int())
+}
+
+func TestAnalyzeExternalStructs(t *testing.T) {
+	pkg, topTypes, err := LoadStarlarkGenTypes("../../test/testdata/split_files")
+	require.NoError(t, err)
+	topTypes, err = FilterTypes(topTypes, []string{"Router"})
+	require.NoError(t, err)
+
+	c := NewContext(pkg, DefaultRuntime)
+	c.SetExternalStructs([]string{"Backend"})
+	b, err := Analyze(c, topTypes, NamingOptions{})
+	require.NoError(t, err)
+
+	// Retry is only reachable through Backend, so it's left out too.
+	require.Len(t, b.Structs, 1)
+	backend := b.Structs[0]
+	assert.Equal(t, "Backend", backend.StarlarkType)
+	assert.True(t, backend.External)
+	assert.Empty(t, backend.Fields)
+	assert.Same(t, backend, b.Objects[0].Fields[3].Converter.Struct)
+
+	names := []string{}
+	for _, builtin := range b.Builtins() {
+		names = append(names, builtin.Name)
+	}
+	assert.Equal(t, []string{"split_files.router"}, names)
+
+	c = NewContext(pkg, DefaultRuntime)
+	c.SetExternalStructs([]string{"Backend", "Retyr"})
+	_, err = Analyze(c, topTypes, NamingOptions{})
+	assert.EqualError(t, err, "external struct Retyr isn't nested in the generated types")
+}
+
+func TestAnalyzeHelperPrefix(t *testing.T) {
+	assert.Equal(t, "", HelperPrefix(""))
+	assert.Equal(t, "", HelperPrefix(DefaultRegisterFunc))
+	assert.Equal(t, "Routes", HelperPrefix("registerRoutes"))
+	assert.Equal(t, "Registry", HelperPrefix("registry"))
+	assert.Equal(t, "AddRoutes", HelperPrefix("addRoutes"))
+
+	pkg := &types.Package{Path: "example.com/api", Name: "api"}
+	c := NewContext(pkg, DefaultRuntime)
+	c.SetHelperPrefix("Routes")
+	b, err := Analyze(c, []*types.Type{widgetType(
+		types.Member{Name: "Ports", Type: &types.Type{Kind: types.Slice, Elem: types.Int32}},
+		types.Member{Name: "Weights", Type: &types.Type{Kind: types.Map, Key: types.String, Elem: types.Int64}},
+	)}, NamingOptions{})
+	require.NoError(t, err)
+	require.Len(t, b.ScalarLists, 1)
+	assert.Equal(t, "RoutesInt32List", b.ScalarLists[0].ListType)
+	require.Len(t, b.Maps, 1)
+	assert.Equal(t, "RoutesInt64Map", b.Maps[0].MapType)
+}
+
+func TestAnalyzePointerLists(t *testing.T) {
+	b := analyzeTestdata(t, "pointer_lists")
+
+	structs := map[string]*Struct{}
+	for _, s := range b.Structs {
+		structs[s.StarlarkType] = s
+	}
+	assert.True(t, structs["Probe"].PointerList)
+	assert.True(t, structs["Header"].PointerList)
+	assert.False(t, structs["Container"].PointerList)
+
+	probes := b.Objects[0].Fields[0].Converter
+	assert.Equal(t, StructListConverter, probes.Kind)
+	assert.True(t, probes.ElemPointer())
+	assert.Same(t, structs["Probe"], probes.Struct)
+	assert.False(t, b.Objects[0].Fields[1].Converter.ElemPointer())
+}
+
+func TestAnalyzeNamedCollections(t *testing.T) {
+	b := analyzeTestdata(t, "named_collections")
+
+	fields := b.Objects[0].Fields
+	assert.Equal(t, []fieldSummary{
+		{"args", "Spec.Args", StringListConverter},
+		{"env", "Spec.Env", StructListConverter},
+		{"selector", "Spec.Selector", StringMapConverter},
+		{"ports", "Spec.Ports", ScalarListConverter},
+		{"inputs", "Spec.Inputs", LocalPathListConverter},
+		{"tasks", "Spec.Tasks", StructListConverter},
+	}, summarize(fields))
+	assert.Equal(t, "EnvVars", fields[1].Converter.Named().Name.Name)
+	assert.Nil(t, fields[5].Converter.Named())
+
+	names := []string{}
+	for _, m := range b.Maps {
+		names = append(names, m.MapType)
+	}
+	assert.Equal(t, []string{"ArgsMap", "EnvVarsMap", "Int64Map"}, names)
+
+	var probes *Converter
+	for _, s := range b.Structs {
+		if s.StarlarkType == "Task" {
+			probes = s.Fields[4].Converter
+		}
+	}
+	require.NotNil(t, probes)
+	assert.Equal(t, StructListConverter, probes.Kind)
+	assert.True(t, probes.ElemPointer())
+	assert.True(t, probes.Struct.PointerList)
+}
+
+func TestAnalyzeMaps(t *testing.T) {
+	b := analyzeTestdata(t, "maps")
+
+	names := []string{}
+	for _, m := range b.Maps {
+		names = append(names, m.MapType)
+	}
+	assert.Equal(t, []string{
+		"BackendListMap", "BackendMap", "BoolMap", "Int32ListMap", "Int64Map", "ModeMap", "StringListMap",
+	}, names)
+
+	assert.Equal(t, []fieldSummary{
+		{"weights", "Spec.Weights", MapConverter},
+		{"backends", "Spec.Backends", MapConverter},
+		{"hosts", "Spec.Hosts", MapConverter},
+		{"pools", "Spec.Pools", MapConverter},
+		{"env", "Spec.Env", StringMapConverter},
+		{"modes", "Spec.Modes", MapConverter},
+	}, summarize(b.Objects[0].Fields))
+
+	pools := b.Objects[0].Fields[3].Converter.Map
+	assert.Equal(t, StructListConverter, pools.Elem.Kind)
+	assert.Equal(t, "Backend", pools.Elem.Struct.StarlarkType)
+}
+
+// Analyzes a Widget object whose spec has the given members.
+func analyzeWidget(specMembers ...types.Member) (*Bindings, error) {
+	pkg := &types.Package{Path: "example.com/api", Name: "api"}
+	return Analyze(NewContext(pkg, DefaultRuntime), []*types.Type{widgetType(specMembers...)}, NamingOptions{})
+}
+
+// A top-level Widget type with the given spec members.
+func widgetType(specMembers ...types.Member) *types.Type {
+	spec := &types.Type{
+		Name:    types.Name{Package: "example.com/api", Name: "WidgetSpec"},
+		Kind:    types.Struct,
+		Members: specMembers,
+	}
+	widget := &types.Type{
+		Name:    types.Name{Package: "example.com/api", Name: "Widget"},
+		Kind:    types.Struct,
+		Members: []types.Member{{Name: "Spec", Type: spec}},
+	}
+	return widget
+}
+
+func TestAnalyzeUnsupportedMembers(t *testing.T) {
+	limits := &types.Type{
+		Name: types.Name{Package: "example.com/api", Name: "Limits"},
+		Kind: types.Struct,
+		Members: []types.Member{
+			{Name: "Burst", Type: types.Int64},
+			{Name: "Priority", Type: types.Byte},
+		},
+	}
+
+	cases := []struct {
+		name   string
+		member types.Member
+		err    string
+	}{
+		{
+			name: "map key",
+			member: types.Member{
+				Name: "Sizes",
+				Type: &types.Type{Name: types.Name{Name: "map[int32]string"}, Kind: types.Map, Key: types.Int32, Elem: types.String},
+			},
+			err: "generating type Widget: Cannot unpack member Sizes: map keys must be strings, got int32",
+		},
+		{
+			name: "chan",
+			member: types.Member{
+				Name: "Events",
+				Type: &types.Type{Kind: types.Chan, Elem: types.String},
+			},
+			err: "generating type Widget: Cannot unpack member Events",
+		},
+		{
+			name:   "byte attribute",
+			member: types.Member{Name: "Limits", Type: limits},
+			err:    "generating Limits unpacker: Unable to unpack attribute Priority: int8 and uint8 are not supported",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			_, err := analyzeWidget(c.member)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestAnalyzeRenames(t *testing.T) {
+	pkg, topTypes, err := LoadStarlarkGenTypes("../../test/testdata/renames")
+	require.NoError(t, err)
+
+	_, err = Analyze(NewContext(pkg, DefaultRuntime), topTypes, NamingOptions{
+		Renames: map[string]string{"RouteSpec.Name": "route_name"},
+	})
+	assert.EqualError(t, err, `generating type Route: argument "url_path" for RouteSpec.UrlPath collides with RouteSpec.URLPath. `+
+		`Rename it with --rename RouteSpec.UrlPath=<name>`)
+
+	_, err = Analyze(NewContext(pkg, DefaultRuntime), topTypes, NamingOptions{
+		Renames: map[string]string{
+			"RouteSpec.Name":    "route_name",
+			"RouteSpec.UrlPath": "legacy_url_path",
+			"RouteSpec.Nmae":    "typo",
+		},
+	})
+	assert.EqualError(t, err, "rename doesn't match any field: RouteSpec.Nmae")
+
+	_, err = Analyze(NewContext(pkg, DefaultRuntime), topTypes, NamingOptions{
+		Renames: map[string]string{"RouteSpec.Name": "route-name"},
+	})
+	assert.EqualError(t, err, "rename RouteSpec.Name=route-name: not a valid argument name")
+
+	_, err = Analyze(NewContext(pkg, DefaultRuntime), topTypes, NamingOptions{
+		Renames: map[string]string{"RouteSpec.Name": "load"},
+	})
+	assert.EqualError(t, err, "rename RouteSpec.Name=load: not a valid argument name")
+}
+
+func TestAnalyzeRenamesFilteredTypes(t *testing.T) {
+	pkg, topTypes, err := LoadStarlarkGenTypes("../../test/testdata/shared_naming_config")
+	require.NoError(t, err)
+	topTypes, err = FilterTypes(topTypes, []string{"Route"})
+	require.NoError(t, err)
+
+	// Renames for the types that were left out are ignored.
+	renames := map[string]string{
+		"RouteSpec.Name":  "route_name",
+		"TunnelSpec.Port": "tunnel_port",
+		"Backend.Host":    "backend_host",
+	}
+	_, err = Analyze(NewContext(pkg, DefaultRuntime), topTypes, NamingOptions{Renames: renames})
+	require.NoError(t, err)
+
+	// Typos in the types and fields we generate still fail, and so do
+	// types that aren't in the package.
+	renames["RouteSpec.Nmae"] = "typo"
+	renames["TunelSpec.Port"] = "typo"
+	_, err = Analyze(NewContext(pkg, DefaultRuntime), topTypes, NamingOptions{Renames: renames})
+	assert.EqualError(t, err, "rename doesn't match any field: RouteSpec.Nmae, TunelSpec.Port")
+}
+
+func TestStarlarkNameTag(t *testing.T) {
+	m := types.Member{Name: "WatchedPaths", CommentLines: []string{"+tilt:starlark-name=paths"}}
+	name, err := starlarkNameTag(m)
+	require.NoError(t, err)
+	assert.Equal(t, "paths", name)
+
+	m.CommentLines = []string{"The paths to watch."}
+	name, err = starlarkNameTag(m)
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+
+	m.CommentLines = []string{"+tilt:starlark-name=watched-paths"}
+	_, err = starlarkNameTag(m)
+	assert.EqualError(t, err, "member WatchedPaths: +tilt:starlark-name=watched-paths is not a valid argument name")
+
+	// Starlark can't pass keywords as arguments.
+	for _, keyword := range []string{"def", "pass", "load", "lambda", "yield"} {
+		m.CommentLines = []string{"+tilt:starlark-name=" + keyword}
+		_, err = starlarkNameTag(m)
+		assert.EqualError(t, err, "member WatchedPaths: +tilt:starlark-name="+keyword+" is not a valid argument name")
+	}
+}
+
+func TestAnalyzeKeywordArgument(t *testing.T) {
+	_, err := analyzeWidget(types.Member{Name: "Pass", Type: types.String})
+	assert.EqualError(t, err, `generating type Widget: argument "pass" for WidgetSpec.Pass is a Starlark keyword. `+
+		`Rename it with --rename WidgetSpec.Pass=<name>`)
+}
@@ -0,0 +1,368 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"k8s.io/gengo/types"
+)
+
+// Initialisms that are written in all caps in Go names, e.g., the HTTP
+// in HTTPGet. Each one is converted as a single word, so HTTPGet becomes
+// http_get and httpGet, instead of h_t_t_p_get or hTTPGet.
+var DefaultInitialisms = []string{
+	"API", "CPU", "DNS", "HTTP", "HTTPS", "ID", "IP", "JSON",
+	"TCP", "TLS", "UDP", "UI", "URI", "URL", "UUID", "YAML",
+}
+
+// Where argument names come from.
+type ArgNameSource string
+
+const (
+	// The Go field name, e.g., watched_paths for WatchedPaths.
+	ArgNamesFromGo ArgNameSource = "go"
+
+	// The name in the field's json tag, e.g., watched_paths for
+	// `json:"watchedPaths"`, so that arguments match the serialized API.
+	// Fields tagged `json:"-"` are skipped.
+	ArgNamesFromJSON ArgNameSource = "json"
+)
+
+// Settings that control how Go fields map to Starlark argument names.
+//
+// They can be read from a JSON config file with LoadNamingOptions, e.g.,
+//
+//	{
+//	  "argNames": "json",
+//	  "initialisms": ["IPs"],
+//	  "renames": {"WidgetSpec.Name": "widget_name"}
+//	}
+type NamingOptions struct {
+	// Where argument names come from. Defaults to ArgNamesFromGo.
+	ArgNames ArgNameSource `json:"argNames,omitempty"`
+
+	// Lower camel case names to use for whole Go names, when the initialisms
+	// aren't enough, e.g., "UIButton" -> "uiButton".
+	Acronyms map[string]string `json:"acronyms,omitempty"`
+
+	// Initialisms to convert as a single word, in addition to
+	// DefaultInitialisms, e.g., "IPs" so that PodIPs becomes pod_ips.
+	Initialisms []string `json:"initialisms,omitempty"`
+
+	// Argument names to use instead of the default, keyed by the Go type
+	// whose members become the arguments and the member name,
+	// e.g., "WidgetSpec.Name" -> "widget_name".
+	//
+	// For top-level objects, the type is the type of the Spec field.
+	Renames map[string]string `json:"renames,omitempty"`
+}
+
+// Reads naming options from a JSON config file, then adds the given options
+// on top, e.g., from flags. If the path is empty, returns the given options.
+func LoadNamingOptions(configPath string, overrides NamingOptions) (NamingOptions, error) {
+	if configPath == "" {
+		return overrides, nil
+	}
+
+	contents, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return NamingOptions{}, fmt.Errorf("loading naming config: %v", err)
+	}
+
+	opts := NamingOptions{}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&opts)
+	if err != nil {
+		return NamingOptions{}, fmt.Errorf("loading naming config %s: %v", configPath, err)
+	}
+
+	if overrides.ArgNames != "" {
+		opts.ArgNames = overrides.ArgNames
+	}
+	opts.Acronyms = mergeNames(opts.Acronyms, overrides.Acronyms)
+	opts.Renames = mergeNames(opts.Renames, overrides.Renames)
+	opts.Initialisms = append(opts.Initialisms, overrides.Initialisms...)
+	return opts, nil
+}
+
+func mergeNames(base, overrides map[string]string) map[string]string {
+	result := map[string]string{}
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range overrides {
+		result[k] = v
+	}
+	return result
+}
+
+// Converts Go names to other cases, honoring the acronyms and initialisms.
+type nameConverter struct {
+	acronyms map[string]string
+
+	// Longest first, so that HTTPS wins over HTTP.
+	initialisms []string
+}
+
+func newNameConverter(opts NamingOptions) *nameConverter {
+	initialisms := append(append([]string{}, DefaultInitialisms...), opts.Initialisms...)
+	sort.SliceStable(initialisms, func(i, j int) bool {
+		return len(initialisms[i]) > len(initialisms[j])
+	})
+	return &nameConverter{acronyms: opts.Acronyms, initialisms: initialisms}
+}
+
+// The snake case of a Go name, e.g., watched_paths for WatchedPaths.
+func (c *nameConverter) snake(goName string) string {
+	return strcase.ToSnake(c.words(goName))
+}
+
+// The lower camel case of a Go name, e.g., httpGet for HTTPGet.
+func (c *nameConverter) lowerCamel(goName string) string {
+	return strcase.ToLowerCamel(c.words(goName))
+}
+
+// The camel case of a Go name, e.g., HttpGet for HTTPGet.
+func (c *nameConverter) camel(goName string) string {
+	return strcase.ToCamel(c.words(goName))
+}
+
+// Rewrites a Go name so that strcase splits it into the right words,
+// e.g., HTTPGet -> HttpGet.
+//
+// An initialism only counts as a word if it isn't in the middle of another
+// run of capitals, and isn't followed by a lowercase letter. So the IP in
+// IPAddress is a word, but the ones in ZIPCode and IPv6 aren't.
+func (c *nameConverter) words(goName string) string {
+	if acronym, ok := c.acronyms[goName]; ok {
+		return acronym
+	}
+
+	out := []byte{}
+	for i := 0; i < len(goName); {
+		prevUpper := len(out) > 0 && isUpper(out[len(out)-1])
+		match := ""
+		for _, w := range c.initialisms {
+			end := i + len(w)
+			if !prevUpper && strings.HasPrefix(goName[i:], w) && (end == len(goName) || !isLower(goName[end])) {
+				match = w
+				break
+			}
+		}
+		if match == "" {
+			out = append(out, goName[i])
+			i++
+			continue
+		}
+		out = append(out, match[0])
+		out = append(out, strings.ToLower(match[1:])...)
+		i += len(match)
+	}
+	return string(out)
+}
+
+func isUpper(b byte) bool {
+	return 'A' <= b && b <= 'Z'
+}
+
+func isLower(b byte) bool {
+	return 'a' <= b && b <= 'z'
+}
+
+// Arguments that every object builtin takes, besides its fields.
+var objectMetaArgs = []string{"name", "labels", "annotations"}
+
+// Assigns Starlark argument names to fields, and checks that each builtin's
+// arguments are unique.
+type kwargNamer struct {
+	opts  NamingOptions
+	names *nameConverter
+
+	// The renames that matched a field.
+	renamed map[string]bool
+
+	// The types we've named arguments for.
+	named map[string]bool
+}
+
+func newKwargNamer(opts NamingOptions, names *nameConverter) *kwargNamer {
+	return &kwargNamer{opts: opts, names: names, renamed: map[string]bool{}, named: map[string]bool{}}
+}
+
+// The arguments of a single builtin.
+type kwargSet struct {
+	namer *kwargNamer
+
+	// The Go type whose members become the arguments, e.g., WidgetSpec.
+	typeName string
+
+	// What each argument name is used for, for error messages.
+	owners map[string]string
+}
+
+// Starts naming the arguments of a builtin. reserved are the arguments
+// the builtin takes besides its fields.
+func (n *kwargNamer) newSet(typeName string, reserved []string) *kwargSet {
+	n.named[typeName] = true
+	set := &kwargSet{namer: n, typeName: typeName, owners: map[string]string{}}
+	for _, name := range reserved {
+		set.owners[name] = fmt.Sprintf("the built-in %s argument", name)
+	}
+	return set
+}
+
+// Names the argument for a member.
+//
+// The name is the snake case of the Go name or the json name, unless the
+// member has a +tilt:starlark-name tag. A --rename overrides all of them.
+//
+// Returns an error if another argument already has that name.
+func (s *kwargSet) add(m types.Member) (string, error) {
+	key := s.typeName + "." + m.Name
+	name, err := starlarkNameTag(m)
+	if err != nil {
+		return "", err
+	}
+	if name == "" && s.namer.opts.ArgNames == ArgNamesFromJSON {
+		name = s.namer.names.snake(jsonName(m))
+	}
+	if name == "" {
+		name = s.namer.names.snake(m.Name)
+	}
+	if rename, ok := s.namer.opts.Renames[key]; ok {
+		s.namer.renamed[key] = true
+		name = rename
+	}
+
+	if starlarkKeywords[name] {
+		return "", fmt.Errorf("argument %q for %s is a Starlark keyword. Rename it with --rename %s=<name>",
+			name, key, key)
+	}
+	if owner, ok := s.owners[name]; ok {
+		return "", fmt.Errorf("argument %q for %s collides with %s. Rename it with --rename %s=<name>",
+			name, key, owner, key)
+	}
+	s.owners[name] = key
+	return name, nil
+}
+
+// The name in a member's json tag, e.g., watchedPaths for
+// `json:"watchedPaths,omitempty"`, or the empty string if it doesn't have one.
+func jsonName(m types.Member) string {
+	name := strings.Split(reflect.StructTag(m.Tags).Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Whether a member is left out of the builtins and unpackers, because it's
+// tagged +tilt:starlark-skip, or because it isn't serialized and argument
+// names come from json tags.
+func (o NamingOptions) skips(m types.Member) (bool, error) {
+	skip, err := isSkippedMember(m)
+	if err != nil || skip {
+		return skip, err
+	}
+	return o.ArgNames == ArgNamesFromJSON && reflect.StructTag(m.Tags).Get("json") == "-", nil
+}
+
+// The name in a member's +tilt:starlark-name tag, or the empty string
+// if it doesn't have one, e.g.,
+//
+//	// +tilt:starlark-name=path
+//	WatchedPaths []string
+func starlarkNameTag(m types.Member) (string, error) {
+	values := types.ExtractCommentTags("+", m.CommentLines)["tilt:starlark-name"]
+	if len(values) == 0 {
+		return "", nil
+	}
+	if len(values) > 1 {
+		return "", fmt.Errorf("member %s has more than one +tilt:starlark-name tag", m.Name)
+	}
+	if !isValidArgName(values[0]) {
+		return "", fmt.Errorf("member %s: +tilt:starlark-name=%s is not a valid argument name", m.Name, values[0])
+	}
+	return values[0], nil
+}
+
+// Starlark's keywords, and the words it reserves. Starlark code can't pass
+// arguments with these names, e.g., f(pass=1) is a syntax error.
+var starlarkKeywords = map[string]bool{
+	"and": true, "break": true, "continue": true, "def": true, "elif": true,
+	"else": true, "for": true, "if": true, "in": true, "lambda": true,
+	"load": true, "not": true, "or": true, "pass": true, "return": true,
+	"while": true,
+
+	"as": true, "assert": true, "async": true, "await": true, "class": true,
+	"del": true, "except": true, "finally": true, "from": true, "global": true,
+	"import": true, "is": true, "nonlocal": true, "raise": true, "try": true,
+	"with": true, "yield": true,
+}
+
+// Whether Starlark code can pass an argument with this name.
+func isValidArgName(name string) bool {
+	return token.IsIdentifier(name) && !starlarkKeywords[name]
+}
+
+// Checks that the argument name source is known, that the initialisms are
+// capitalized words, and that the renames are valid argument names.
+func (o NamingOptions) validate() error {
+	switch o.ArgNames {
+	case "", ArgNamesFromGo, ArgNamesFromJSON:
+	default:
+		return fmt.Errorf("unknown argument name source %q (must be %s or %s)", o.ArgNames, ArgNamesFromGo, ArgNamesFromJSON)
+	}
+
+	for _, w := range o.Initialisms {
+		if !token.IsIdentifier(w) || !isUpper(w[0]) {
+			return fmt.Errorf("initialism %q: must be a word that starts with a capital letter", w)
+		}
+	}
+
+	keys := []string{}
+	for key := range o.Renames {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := o.Renames[key]
+		if !isValidArgName(name) {
+			return fmt.Errorf("rename %s=%s: not a valid argument name", key, name)
+		}
+	}
+	return nil
+}
+
+// Checks that all the renames matched a field, so that typos don't go unnoticed.
+//
+// Renames for types in the package that we didn't generate builtins for,
+// e.g., because --types left them out, are ignored, so that several runs
+// can share a naming config.
+func (n *kwargNamer) checkUnused(pkg *types.Package) error {
+	unused := []string{}
+	for key := range n.opts.Renames {
+		if n.renamed[key] {
+			continue
+		}
+		typeName := strings.SplitN(key, ".", 2)[0]
+		if !n.named[typeName] && pkg.Types[typeName] != nil {
+			continue
+		}
+		unused = append(unused, key)
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return fmt.Errorf("rename doesn't match any field: %s", strings.Join(unused, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package codegen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/gengo/types"
+)
+
+func TestNameConverter(t *testing.T) {
+	c := newNameConverter(NamingOptions{
+		Acronyms:    map[string]string{"GitLFSPointer": "gitLfsPointer"},
+		Initialisms: []string{"IPs"},
+	})
+
+	assert.Equal(t, "http_get", c.snake("HTTPGet"))
+	assert.Equal(t, "httpGet", c.lowerCamel("HTTPGet"))
+	assert.Equal(t, "HttpGet", c.camel("HTTPGet"))
+	assert.Equal(t, "tcpSocket", c.lowerCamel("TCPSocket"))
+	assert.Equal(t, "ipAddress", c.lowerCamel("IPAddress"))
+	assert.Equal(t, "uiButton", c.lowerCamel("UIButton"))
+	assert.Equal(t, "https_port", c.snake("HTTPSPort"))
+	assert.Equal(t, "http_url", c.snake("HTTPURL"))
+	assert.Equal(t, "watched_paths", c.snake("WatchedPaths"))
+
+	// Initialisms from the options.
+	assert.Equal(t, "pod_ips", c.snake("PodIPs"))
+	assert.Equal(t, "podIps", c.lowerCamel("PodIPs"))
+
+	// Not a word on its own.
+	assert.Equal(t, "zip_code", c.snake("ZIPCode"))
+	assert.Equal(t, "iPv6", c.lowerCamel("IPv6"))
+
+	// Acronyms replace the whole name.
+	assert.Equal(t, "git_lfs_pointer", c.snake("GitLFSPointer"))
+	assert.Equal(t, "gitLfsPointer", c.lowerCamel("GitLFSPointer"))
+}
+
+func TestLoadNamingOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "naming.json")
+	err := ioutil.WriteFile(path, []byte(`{
+  "initialisms": ["IPs"],
+  "renames": {"WidgetSpec.Name": "widget_name", "WidgetSpec.Kind": "widget_kind"}
+}`), 0644)
+	require.NoError(t, err)
+
+	opts, err := LoadNamingOptions(path, NamingOptions{
+		Initialisms: []string{"SHA"},
+		Renames:     map[string]string{"WidgetSpec.Kind": "kind_of_widget"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"IPs", "SHA"}, opts.Initialisms)
+	assert.Equal(t, map[string]string{
+		"WidgetSpec.Name": "widget_name",
+		"WidgetSpec.Kind": "kind_of_widget",
+	}, opts.Renames)
+
+	err = ioutil.WriteFile(path, []byte(`{"initialism": ["IPs"]}`), 0644)
+	require.NoError(t, err)
+	_, err = LoadNamingOptions(path, NamingOptions{})
+	assert.Contains(t, err.Error(), `unknown field "initialism"`)
+}
+
+func TestJSONNames(t *testing.T) {
+	opts := NamingOptions{ArgNames: ArgNamesFromJSON}
+
+	m := types.Member{Name: "ImageRef", Tags: `json:"image,omitempty"`}
+	assert.Equal(t, "image", jsonName(m))
+	skip, err := opts.skips(m)
+	require.NoError(t, err)
+	assert.False(t, skip)
+
+	m = types.Member{Name: "Replicas", Tags: `json:",omitempty"`}
+	assert.Equal(t, "", jsonName(m))
+
+	m = types.Member{Name: "Cache", Tags: `json:"-"`}
+	assert.Equal(t, "", jsonName(m))
+	skip, err = opts.skips(m)
+	require.NoError(t, err)
+	assert.True(t, skip)
+
+	// Only skipped when names come from json tags.
+	skip, err = NamingOptions{}.skips(m)
+	require.NoError(t, err)
+	assert.False(t, skip)
+}
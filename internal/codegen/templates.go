@@ -0,0 +1,183 @@
+package codegen
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"k8s.io/gengo/types"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplateFiles embed.FS
+
+// The templates the generated code is rendered from, by file name
+// without the .tmpl extension.
+var TemplateNames = []string{
+	// The package clause and imports.
+	"preamble",
+
+	// The Plugin method that registers all the builtins.
+	"register",
+
+	// The builtin for a top-level API object.
+	"object",
+
+	// The Starlark type, builtin, and dict unpacker for a nested struct.
+	"struct",
+
+	// The Starlark list type for a nested struct.
+	"list",
+
+	// One case of a struct's dict unpacker, for a single field.
+	"attr",
+
+	// The conversion of a Starlark value to a Go bool, number, or string,
+	// shared by attr and scalarlist.
+	"scalar",
+
+	// The list type for a slice of bools or numbers, e.g., Int32List.
+	"scalarlist",
+
+	// The dict type for a map with values other than strings, e.g., BackendMap.
+	"map",
+}
+
+// A set of text/template templates that the generated code is rendered from.
+//
+// Templates can call each other, e.g., the struct template calls attr
+// for each field. They can also call these functions:
+//
+//	typeName  the Go expression for a type, e.g., v1alpha1.FileWatch
+//	starkit   a name in the runtime's starkit package, e.g., starkit.UnpackArgs
+//	value     a name in the runtime's value package, e.g., value.StringList
+//	convert   the Go expression that converts a value to a field's type
+type Templates struct {
+	tmpl *template.Template
+}
+
+var defaultTemplates = mustLoadDefaultTemplates()
+
+func mustLoadDefaultTemplates() *Templates {
+	t := &Templates{tmpl: template.New("").Funcs(templateFuncs(nil))}
+	for _, name := range TemplateNames {
+		contents, err := defaultTemplateFiles.ReadFile("templates/" + name + ".tmpl")
+		if err != nil {
+			panic(err)
+		}
+		err = t.parse(name, string(contents))
+		if err != nil {
+			panic(err)
+		}
+	}
+	return t
+}
+
+// The templates built into the generator.
+func DefaultTemplates() *Templates {
+	return defaultTemplates
+}
+
+// Loads the default templates, then overrides them with the templates
+// in a directory.
+//
+// Each file in the directory named <name>.tmpl replaces the default template
+// of that name, e.g., attr.tmpl replaces the attr template. Templates that
+// aren't in the directory keep their defaults.
+func LoadTemplates(dir string) (*Templates, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading templates: %v", err)
+	}
+
+	tmpl, err := defaultTemplates.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	t := &Templates{tmpl: tmpl}
+
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || filepath.Ext(fileName) != ".tmpl" {
+			continue
+		}
+
+		name := strings.TrimSuffix(fileName, ".tmpl")
+		if !isTemplateName(name) {
+			return nil, fmt.Errorf("loading templates: unknown template %s (must be one of: %s)",
+				filepath.Join(dir, fileName), strings.Join(sortedTemplateNames(), ", "))
+		}
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("loading templates: %v", err)
+		}
+		err = t.parse(name, string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("loading templates: %v", err)
+		}
+	}
+	return t, nil
+}
+
+func (t *Templates) parse(name, contents string) error {
+	_, err := t.tmpl.New(name).Parse(contents)
+	return err
+}
+
+func isTemplateName(name string) bool {
+	for _, n := range TemplateNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedTemplateNames() []string {
+	names := append([]string{}, TemplateNames...)
+	sort.Strings(names)
+	return names
+}
+
+// The functions templates can call, bound to the file being generated.
+//
+// The templates are parsed with a nil context, because parsing only needs
+// to know the names of the functions.
+func templateFuncs(c *Context) template.FuncMap {
+	return template.FuncMap{
+		"typeName": func(t *types.Type) string {
+			return c.TypeName(t)
+		},
+		"starkit": func(name string) string {
+			return c.Starkit(name)
+		},
+		"value": func(name string) string {
+			return c.Value(name)
+		},
+		"convert": func(conv *Converter, v string) string {
+			return convertValue(conv, v, c)
+		},
+	}
+}
+
+// Renders a template into the generated file.
+func (c *Context) execute(w io.Writer, name string, data interface{}) error {
+	if c.tmpl == nil {
+		tmpl, err := c.templates.tmpl.Clone()
+		if err != nil {
+			return err
+		}
+		c.tmpl = tmpl.Funcs(templateFuncs(c))
+	}
+	err := c.tmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		return err
+	}
+	return c.importErr
+}
@@ -0,0 +1,58 @@
+package codegen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTemplatesOverride(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "list.tmpl"), []byte("// list of {{.StarlarkType}}\n"), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a template"), 0644)
+	require.NoError(t, err)
+
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	b := analyzeTestdata(t, "nested")
+	c := NewContext(b.Pkg, DefaultRuntime)
+	c.SetTemplates(templates)
+
+	buf := bytes.NewBuffer(nil)
+	err = WriteStarlarkStructListFunction(b.Structs[0], c, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "// list of Cache\n", buf.String())
+
+	// Templates that weren't overridden keep their defaults.
+	buf.Reset()
+	err = WriteStarlarkStructFunction(b.Structs[0], c, buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "func (o *Cache) Unpack(v starlark.Value) error {")
+}
+
+func TestLoadTemplatesUnknownName(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "atr.tmpl"), []byte(""), 0644)
+	require.NoError(t, err)
+
+	_, err = LoadTemplates(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown template")
+	assert.Contains(t, err.Error(), "must be one of: attr, list, map, object, preamble, register, scalar, scalarlist, struct")
+}
+
+func TestLoadTemplatesParseError(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "attr.tmpl"), []byte("{{if}"), 0644)
+	require.NoError(t, err)
+
+	_, err = LoadTemplates(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading templates: template: attr:1")
+}
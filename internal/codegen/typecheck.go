@@ -0,0 +1,299 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	gotypes "go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// The hand-written half of the generated package, which the generated
+// code calls into. Type-checking only needs the signatures.
+const typeCheckStub = `package %s
+
+import (
+	"go.starlark.net/starlark"
+	%s
+)
+
+type Plugin struct{}
+
+func (p Plugin) register(t *starlark.Thread, obj interface{}) (starlark.Value, error) {
+	return starlark.None, nil
+}
+`
+
+// The types of an external struct, which another file generates. Only
+// the parts the generated code uses are declared.
+const externalStructStub = `
+type %[1]s struct {
+	Value      api.%[3]s
+	isUnpacked bool
+	t          *starlark.Thread
+}
+
+func (o *%[1]s) Unpack(v starlark.Value) error { return nil }
+
+type %[2]s struct {
+	Value []api.%[3]s
+	t     *starlark.Thread
+}
+
+func (o *%[2]s) Unpack(v starlark.Value) error { return nil }
+
+func (o *%[2]s) Pointers() []*api.%[3]s { return nil }
+`
+
+// The stub of the rest of the package: the hand-written half, plus the
+// external structs, if any.
+func packageStub(b *Bindings, pkgName string) string {
+	apiImport := ""
+	externals := bytes.NewBuffer(nil)
+	for _, s := range b.Structs {
+		if s.External {
+			apiImport = fmt.Sprintf("api %q", b.Pkg.Path)
+			fmt.Fprintf(externals, externalStructStub, s.StarlarkType, s.ListType, s.Type.Name.Name)
+		}
+	}
+	return fmt.Sprintf(typeCheckStub, pkgName, apiImport) + externals.String()
+}
+
+// Type-checks the generated source, and returns an error diagnostic for each
+// problem, e.g., an undefined identifier, a missing import, or a conversion
+// between incompatible types.
+//
+// The packages the source imports are resolved from dir, which should be in
+// a module that can import both the API package and the runtime. If they can't
+// be resolved, returns a single error that the code couldn't be checked.
+//
+// Diagnostics point at positions in fileName, and at the field of the API
+// type that the code was generated for, if any.
+func TypeCheck(b *Bindings, fileName string, src []byte, dir string) []Diagnostic {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, src, 0)
+	if err != nil {
+		return parseDiagnostics(err)
+	}
+
+	stub, err := parser.ParseFile(fset, "", packageStub(b, file.Name.Name), 0)
+	if err != nil {
+		panic(err)
+	}
+
+	paths := []string{starlarkImportPath, b.Pkg.Path}
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	imp, err := loadImports(fset, dir, paths)
+	if err != nil {
+		return []Diagnostic{{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("couldn't type-check the generated code: %v", err),
+		}}
+	}
+
+	result := []Diagnostic{}
+	conf := gotypes.Config{
+		Importer: imp,
+		Error: func(err error) {
+			typeErr, ok := err.(gotypes.Error)
+			if !ok {
+				result = append(result, Diagnostic{Severity: SeverityError, Message: err.Error()})
+				return
+			}
+			result = append(result, Diagnostic{
+				Severity: SeverityError,
+				Message:  typeErr.Msg,
+				Pos:      fset.Position(typeErr.Pos),
+				Field:    fieldAt(b, file, typeErr.Pos),
+			})
+		},
+	}
+	_, _ = conf.Check(file.Name.Name, fset, []*ast.File{file, stub}, nil)
+	return result
+}
+
+// Finds the export data of the given packages and everything they import,
+// compiling them if needed, and returns an importer that reads it.
+func loadImports(fset *token.FileSet, dir string, paths []string) (gotypes.Importer, error) {
+	args := append([]string{"list", "-export", "-deps", "-f", "{{.ImportPath}}={{.Export}}"}, paths...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	exports := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 && parts[1] != "" {
+			exports[parts[0]] = parts[1]
+		}
+	}
+
+	return importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(export)
+	}), nil
+}
+
+func parseDiagnostics(err error) []Diagnostic {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return []Diagnostic{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	result := []Diagnostic{}
+	for _, e := range list {
+		result = append(result, Diagnostic{Severity: SeverityError, Message: e.Msg, Pos: e.Pos})
+	}
+	return result
+}
+
+// Finds the field of the API type that the code at pos was generated for,
+// e.g., Step.Timeout. Returns the empty string if the code isn't specific
+// to a field.
+func fieldAt(b *Bindings, file *ast.File, pos token.Pos) string {
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	builtin, decl := builtinAt(b, path)
+	if builtin == nil {
+		return ""
+	}
+
+	f := fieldInPath(builtin, path, decl.Name.Name != "Unpack")
+	if f == nil {
+		return builtin.Type.Name.Name
+	}
+	return builtin.Type.Name.Name + "." + f.GoName
+}
+
+// Finds the builtin that generated the function enclosing a path.
+func builtinAt(b *Bindings, path []ast.Node) (*Builtin, *ast.FuncDecl) {
+	var decl *ast.FuncDecl
+	for _, n := range path {
+		if d, ok := n.(*ast.FuncDecl); ok {
+			decl = d
+			break
+		}
+	}
+	if decl == nil || decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return nil, nil
+	}
+
+	recv := strings.TrimPrefix(gotypes.ExprString(decl.Recv.List[0].Type), "*")
+	for _, builtin := range b.Builtins() {
+		if recv == "Plugin" && decl.Name.Name == builtin.FuncName {
+			return builtin, decl
+		}
+	}
+	for _, s := range b.Structs {
+		if recv == s.StarlarkType || recv == s.ListType {
+			return &s.Builtin, decl
+		}
+	}
+	return nil, nil
+}
+
+// Finds the field that the innermost node in the path refers to.
+//
+// In builtins, local variables are named after fields, so any statement
+// that refers to one is about that field. In unpackers, they aren't.
+func fieldInPath(builtin *Builtin, path []ast.Node, matchVars bool) *Field {
+	byName := map[string]*Field{}
+	byVar := map[string]*Field{}
+	byTarget := map[string]*Field{}
+	for _, f := range builtin.Fields {
+		byName[f.Name] = f
+		if f.Var != "" {
+			byVar[f.Var] = f
+		}
+		byTarget["obj."+f.GoName] = f
+	}
+
+	for i, n := range path {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			// A case of a struct unpacker: if key == "name" { ... }
+			cond, ok := n.Cond.(*ast.BinaryExpr)
+			if ok && gotypes.ExprString(cond.X) == "key" {
+				if f := byName[stringLit(cond.Y)]; f != nil {
+					return f
+				}
+			}
+
+		case *ast.CallExpr:
+			// An argument to UnpackArgs, which comes after its name: "name?", &var
+			for j := 1; j < len(n.Args) && i > 0; j++ {
+				if n.Args[j] != path[i-1] {
+					continue
+				}
+				name := strings.TrimSuffix(stringLit(n.Args[j-1]), "?")
+				if f := byName[name]; f != nil {
+					return f
+				}
+			}
+		}
+	}
+
+	if !matchVars {
+		return nil
+	}
+
+	// Only look at the innermost statement. Outer ones, like the function body,
+	// refer to every field.
+	for _, n := range path {
+		stmt, ok := n.(ast.Stmt)
+		if !ok {
+			continue
+		}
+		if _, ok := stmt.(*ast.BlockStmt); ok {
+			return nil
+		}
+
+		var found *Field
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				found = byTarget[gotypes.ExprString(n)]
+			case *ast.Ident:
+				found = byVar[n.Name]
+			}
+			return found == nil
+		})
+		return found
+	}
+	return nil
+}
+
+func stringLit(e ast.Expr) string {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return s
+}
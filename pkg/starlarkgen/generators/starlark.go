@@ -0,0 +1,273 @@
+// Package generators packages the Starlark codegen as a k8s.io/gengo
+// generator, so that it can run in the same pipeline as deepcopy-gen
+// and openapi-gen.
+//
+// See cmd/starlark-gen for a binary that uses it.
+package generators
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/gengo/args"
+	"k8s.io/gengo/generator"
+	"k8s.io/gengo/namer"
+	"k8s.io/gengo/types"
+	"k8s.io/klog/v2"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+// Flags specific to the Starlark generator.
+type CustomArgs struct {
+	// 'tilt' or 'standalone'. See starlarkgen.RuntimeByName.
+	Runtime string
+
+	// Override the import paths of the runtime.
+	StarkitPackage string
+	ValuePackage   string
+
+	// The name of the generated Plugin method that registers all the builtins.
+	RegisterFunc string
+
+	// Nested structs that another file in the same package generates.
+	ExternalStructs []string
+
+	// Where argument names come from: go or json.
+	ArgNames string
+
+	// Lower camel case names for whole Go names, e.g., UIButton=uiButton.
+	Acronyms map[string]string
+
+	// Initialisms to convert as a single word, in addition to the defaults.
+	Initialisms []string
+
+	// Argument names to use instead of the default, e.g.,
+	// WidgetSpec.Name=widget_name.
+	Renames map[string]string
+
+	// A JSON file of naming options. The flags are added on top.
+	NamingConfig string
+
+	// A directory of templates that override the default ones.
+	TemplateDir string
+}
+
+func DefaultCustomArgs() *CustomArgs {
+	return &CustomArgs{
+		Runtime:      "tilt",
+		RegisterFunc: codegen.DefaultRegisterFunc,
+		Acronyms:     map[string]string{},
+		Renames:      map[string]string{},
+	}
+}
+
+func (ca *CustomArgs) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&ca.Runtime, "runtime", ca.Runtime, "Runtime helpers the generated code calls: 'tilt' for Tilt's internal packages, or 'standalone' for this repo's pkg/starlarkrt")
+	fs.StringVar(&ca.StarkitPackage, "starkit-package", ca.StarkitPackage, "Import path of the runtime package that provides UnpackArgs and Environment (overrides --runtime)")
+	fs.StringVar(&ca.ValuePackage, "value-package", ca.ValuePackage, "Import path of the runtime package that provides StringList, StringStringMap, LocalPath, LocalPathList and Duration (overrides --runtime)")
+	fs.StringVar(&ca.RegisterFunc, "register-func", ca.RegisterFunc, "Name of the generated Plugin method that registers the builtins")
+	fs.StringSliceVar(&ca.ExternalStructs, "external-structs", ca.ExternalStructs, "Nested structs that another file in the output package generates. The generated code uses their types, but doesn't declare them or register their builtins")
+	fs.StringVar(&ca.TemplateDir, "templates", ca.TemplateDir, "Directory of templates that override the default ones, named after the template they replace (e.g., attr.tmpl)")
+	fs.StringToStringVar(&ca.Acronyms, "acronym", ca.Acronyms, "Acronyms to use when converting Go names, as GoName=starlarkName (e.g., UIButton=uiButton)")
+	fs.StringVar(&ca.ArgNames, "arg-names", ca.ArgNames, "Where argument names come from: 'go' for the snake case of the Go field names (default), or 'json' for the snake case of the json tags")
+	fs.StringSliceVar(&ca.Initialisms, "initialism", ca.Initialisms, "Initialisms to convert as a single word, in addition to the defaults (e.g., IPs, so that PodIPs becomes pod_ips)")
+	fs.StringVar(&ca.NamingConfig, "naming-config", ca.NamingConfig, "JSON file of acronyms, initialisms, and renames. Flags are added on top")
+	fs.StringToStringVar(&ca.Renames, "rename", ca.Renames, "Argument names to use instead of the default, as Type.Field=name (e.g., WidgetSpec.Name=widget_name)")
+}
+
+func (ca *CustomArgs) runtime() (codegen.Runtime, error) {
+	runtime, err := codegen.RuntimeByName(ca.Runtime)
+	if err != nil {
+		return codegen.Runtime{}, fmt.Errorf("--%v", err)
+	}
+	if ca.StarkitPackage != "" {
+		runtime.StarkitPackage = ca.StarkitPackage
+	}
+	if ca.ValuePackage != "" {
+		runtime.ValuePackage = ca.ValuePackage
+	}
+	return runtime, nil
+}
+
+// The name systems available to the generator.
+func NameSystems() namer.NameSystems {
+	return namer.NameSystems{
+		"public": namer.NewPublicNamer(0),
+		"raw":    namer.NewRawNamer("", nil),
+	}
+}
+
+// The name system that determines the order types are generated in.
+func DefaultNameSystem() string {
+	return "public"
+}
+
+// Creates one output package for each input package with types tagged
+// +tilt:starlark-gen=true.
+//
+// The output package is the --output-package path plus the last element of
+// the input path, e.g., github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1 with
+// --output-package=github.com/tilt-dev/tilt/internal/tiltfile generates
+// github.com/tilt-dev/tilt/internal/tiltfile/v1alpha1.
+func Packages(context *generator.Context, arguments *args.GeneratorArgs) generator.Packages {
+	header, err := loadHeader(arguments)
+	if err != nil {
+		klog.Fatalf("Failed loading boilerplate: %v", err)
+	}
+
+	customArgs, ok := arguments.CustomArgs.(*CustomArgs)
+	if !ok {
+		customArgs = DefaultCustomArgs()
+	}
+	runtime, err := customArgs.runtime()
+	if err != nil {
+		klog.Fatalf("%v", err)
+	}
+	naming, err := codegen.LoadNamingOptions(customArgs.NamingConfig, codegen.NamingOptions{
+		ArgNames:    codegen.ArgNameSource(customArgs.ArgNames),
+		Acronyms:    customArgs.Acronyms,
+		Initialisms: customArgs.Initialisms,
+		Renames:     customArgs.Renames,
+	})
+	if err != nil {
+		klog.Fatalf("%v", err)
+	}
+
+	templates := codegen.DefaultTemplates()
+	if customArgs.TemplateDir != "" {
+		templates, err = codegen.LoadTemplates(customArgs.TemplateDir)
+		if err != nil {
+			klog.Fatalf("%v", err)
+		}
+	}
+
+	packages := generator.Packages{}
+	for _, inputDir := range context.Inputs {
+		pkg := context.Universe.Package(inputDir)
+		topTypes, err := codegen.FindStarlarkGenTypes(pkg)
+		if err != nil {
+			klog.Fatalf("%v", err)
+		}
+		if len(topTypes) == 0 {
+			klog.V(5).Infof("Skipping package %s: no types tagged +tilt:starlark-gen=true", pkg.Path)
+			continue
+		}
+
+		c := codegen.NewContext(pkg, runtime)
+		c.SetTemplates(templates)
+		c.SetHelperPrefix(codegen.HelperPrefix(customArgs.RegisterFunc))
+		c.SetExternalStructs(customArgs.ExternalStructs)
+		bindings, err := codegen.Analyze(c, topTypes, naming)
+		if err != nil {
+			klog.Fatalf("%v", err)
+		}
+
+		outPath := path.Join(arguments.OutputPackagePath, path.Base(pkg.Path))
+		gen := newStarlarkGen(arguments.OutputFileBaseName, bindings, c, customArgs.RegisterFunc)
+		packages = append(packages, &generator.DefaultPackage{
+			PackageName: path.Base(outPath),
+			PackagePath: outPath,
+			HeaderText:  header,
+			GeneratorFunc: func(c *generator.Context) []generator.Generator {
+				return []generator.Generator{gen}
+			},
+			FilterFunc: gen.Filter,
+		})
+	}
+	return packages
+}
+
+// Unlike the k8s generators, the boilerplate file is optional.
+func loadHeader(arguments *args.GeneratorArgs) ([]byte, error) {
+	if arguments.GoHeaderFilePath != "" {
+		return arguments.LoadGoBoilerplate()
+	}
+	comment := strings.Replace(arguments.GeneratedByCommentTemplate, "GENERATOR_NAME", "starlark-gen", -1)
+	if comment == "" {
+		return nil, nil
+	}
+	return []byte(comment + "\n\n"), nil
+}
+
+// Generates the builtins for one API package.
+type starlarkGen struct {
+	generator.DefaultGen
+	c            *codegen.Context
+	bindings     *codegen.Bindings
+	registerFunc string
+	objects      map[*types.Type]*codegen.Object
+	structs      map[*types.Type]*codegen.Struct
+}
+
+// The context must be the one the bindings were analyzed with.
+func newStarlarkGen(fileBaseName string, bindings *codegen.Bindings, c *codegen.Context, registerFunc string) *starlarkGen {
+	g := &starlarkGen{
+		DefaultGen:   generator.DefaultGen{OptionalName: fileBaseName},
+		c:            c,
+		bindings:     bindings,
+		registerFunc: registerFunc,
+		objects:      map[*types.Type]*codegen.Object{},
+		structs:      map[*types.Type]*codegen.Struct{},
+	}
+	for _, o := range bindings.Objects {
+		g.objects[o.Type] = o
+	}
+	for _, s := range bindings.Structs {
+		if !s.External {
+			g.structs[s.Type] = s
+		}
+	}
+	return g
+}
+
+func (g *starlarkGen) Filter(c *generator.Context, t *types.Type) bool {
+	return g.objects[t] != nil || g.structs[t] != nil
+}
+
+func (g *starlarkGen) Namers(c *generator.Context) namer.NameSystems {
+	return namer.NameSystems{"raw": g.c.Namer()}
+}
+
+func (g *starlarkGen) Imports(c *generator.Context) []string {
+	first, second := g.c.ImportGroups()
+	return append(first, second...)
+}
+
+func (g *starlarkGen) Init(c *generator.Context, w io.Writer) error {
+	return codegen.WriteStarlarkRegistrationFunc(g.bindings, g.c, g.registerFunc, w)
+}
+
+func (g *starlarkGen) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
+	if o, ok := g.objects[t]; ok {
+		klog.V(5).Infof("Generating builtin for %s", t.Name)
+		return codegen.WriteStarlarkAPIObjectFunction(o, g.c, w)
+	}
+
+	klog.V(5).Infof("Generating struct for %s", t.Name)
+	s := g.structs[t]
+	err := codegen.WriteStarlarkStructFunction(s, g.c, w)
+	if err != nil {
+		return err
+	}
+	return codegen.WriteStarlarkStructListFunction(s, g.c, w)
+}
+
+func (g *starlarkGen) Finalize(c *generator.Context, w io.Writer) error {
+	for _, l := range g.bindings.ScalarLists {
+		err := codegen.WriteStarlarkScalarListFunction(l, g.c, w)
+		if err != nil {
+			return err
+		}
+	}
+	for _, m := range g.bindings.Maps {
+		err := codegen.WriteStarlarkMapFunction(m, g.c, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Package starlarkgen generates Starlark builtins for Kubernetes-style API
+// types.
+//
+// It's the library behind the tilt-starlark-codegen binary, for tools that
+// want to run the generator without shelling out, e.g.:
+//
+//	result, err := starlarkgen.Generate(starlarkgen.Options{
+//		InputPackage: "./pkg/apis/core/v1alpha1",
+//		Runtime:      starlarkgen.StandaloneRuntime,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	_, err = starlarkgen.WriteFile("./internal/tiltfile/v1alpha1", result)
+package starlarkgen
+
+import (
+	"go/token"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+// The default name of the generated file.
+const DefaultFileName = codegen.DefaultOutputFileName
+
+// The default name of the generated method that registers all the builtins.
+const DefaultRegisterFunc = codegen.DefaultRegisterFunc
+
+// The packages that provide the helpers the generated code calls at runtime.
+type Runtime struct {
+	// Import path of the package that provides UnpackArgs and Environment.
+	StarkitPackage string
+
+	// Import path of the package that provides StringList, StringStringMap,
+	// LocalPath, LocalPathList, and Duration.
+	//
+	// May be the same as StarkitPackage.
+	ValuePackage string
+}
+
+// The helpers in the Tilt codebase. These are internal packages, so code that
+// uses them only compiles inside Tilt.
+var TiltRuntime = Runtime(codegen.DefaultRuntime)
+
+// The helpers in this repo's pkg/starlarkrt package, which any module can import.
+var StandaloneRuntime = Runtime(codegen.StandaloneRuntime)
+
+// Looks up a runtime by the name used on the command line:
+// "tilt" for TiltRuntime, or "standalone" for StandaloneRuntime.
+func RuntimeByName(name string) (Runtime, error) {
+	runtime, err := codegen.RuntimeByName(name)
+	return Runtime(runtime), err
+}
+
+// The names of the templates that Options.TemplateDir can override:
+// preamble, register, object, struct, list, attr, scalar, scalarlist, and map.
+var TemplateNames = codegen.TemplateNames
+
+// Where argument names come from.
+type ArgNameSource string
+
+const (
+	// The Go field name, e.g., watched_paths for WatchedPaths.
+	ArgNamesFromGo ArgNameSource = ArgNameSource(codegen.ArgNamesFromGo)
+
+	// The snake case of the name in the field's json tag, e.g., watched_paths
+	// for `json:"watchedPaths"`. Fields tagged `json:"-"` are skipped.
+	ArgNamesFromJSON ArgNameSource = ArgNameSource(codegen.ArgNamesFromJSON)
+)
+
+// The initialisms that are always converted as a single word, e.g., the
+// HTTP in HTTPGet, which becomes http_get. Options.Initialisms adds to these.
+var DefaultInitialisms = codegen.DefaultInitialisms
+
+// Options for a single run of the generator. InputPackage is required,
+// and everything else has a default.
+type Options struct {
+	// The API package to read types from. May be a directory
+	// (starting with "." or "/") or an import path.
+	InputPackage string
+
+	// The name of the generated Go package.
+	// Defaults to the name of the input package.
+	OutputPackage string
+
+	// The name of the generated Plugin method that registers all the builtins.
+	// Defaults to DefaultRegisterFunc. Set this when generating several files
+	// into the same package. The generated helper types are prefixed with it,
+	// e.g., registerRoutes generates RoutesInt32List instead of Int32List.
+	RegisterFunc string
+
+	// Nested structs that another file in the same package generates, e.g.,
+	// because top-level types in both files have fields of that type. The
+	// generated code uses their types, but doesn't declare them or register
+	// their builtins. Structs only reachable through them are left out too.
+	ExternalStructs []string
+
+	// The packages that provide the helpers the generated code calls.
+	// Defaults to TiltRuntime. Missing import paths are filled in from
+	// TiltRuntime.
+	Runtime Runtime
+
+	// If non-empty, only generate builtins for these top-level types.
+	// Otherwise, generates builtins for all types tagged with +tilt:starlark-gen=true.
+	Types []string
+
+	// Where argument names come from. Defaults to ArgNamesFromGo.
+	ArgNames ArgNameSource
+
+	// Lower camel case names for whole Go names, when the initialisms
+	// aren't enough, e.g., "UIButton" -> "uiButton".
+	Acronyms map[string]string
+
+	// Initialisms to convert as a single word, in addition to
+	// DefaultInitialisms, e.g., "IPs" so that PodIPs becomes pod_ips
+	// instead of pod_i_ps.
+	Initialisms []string
+
+	// Argument names to use instead of the default, keyed by the Go type whose
+	// members become the arguments and the member name, e.g.,
+	// "WidgetSpec.Name" -> "widget_name". For top-level objects, the type is
+	// the type of the Spec field.
+	//
+	// Generate fails if two arguments of a builtin have the same name,
+	// so renames are how to resolve collisions.
+	Renames map[string]string
+
+	// A JSON file with any of the naming options above, e.g.,
+	//
+	//	{
+	//	  "argNames": "json",
+	//	  "acronyms": {"UIButton": "uiButton"},
+	//	  "initialisms": ["IPs"],
+	//	  "renames": {"WidgetSpec.Name": "widget_name"}
+	//	}
+	//
+	// The options set here are added on top of the file's. May be empty.
+	NamingConfig string
+
+	// The name of the generated file, used for positions in diagnostics and
+	// by WriteFile. Must be a .go file name without a directory.
+	// Defaults to DefaultFileName.
+	FileName string
+
+	// Whether to type-check the generated code against the API package and
+	// the runtime, and report problems like undefined identifiers, missing
+	// imports, and bad conversions as SeverityError diagnostics.
+	//
+	// Imports are resolved from InputPackage's directory, so the module it's in
+	// must be able to import the runtime. If it can't, the code isn't checked,
+	// and there's a SeverityError diagnostic saying so.
+	TypeCheck bool
+
+	// A directory of templates that override the ones the generated code is
+	// rendered from. Each file is named after the template it replaces,
+	// e.g., attr.tmpl. See TemplateNames.
+	TemplateDir string
+
+	// Prints progress messages. May be nil.
+	Logf func(format string, args ...interface{})
+}
+
+func (o Options) codegenOptions() codegen.Options {
+	return codegen.Options{
+		InputPackage:    o.InputPackage,
+		OutputPackage:   o.OutputPackage,
+		RegisterFunc:    o.RegisterFunc,
+		ExternalStructs: o.ExternalStructs,
+		Runtime:         codegen.Runtime(o.Runtime),
+		Types:           o.Types,
+		ArgNames:        codegen.ArgNameSource(o.ArgNames),
+		Acronyms:        o.Acronyms,
+		Initialisms:     o.Initialisms,
+		Renames:         o.Renames,
+		NamingConfig:    o.NamingConfig,
+		FileName:        o.FileName,
+		TypeCheck:       o.TypeCheck,
+		TemplateDir:     o.TemplateDir,
+		Logf:            o.Logf,
+	}
+}
+
+// How bad a diagnostic is.
+type Severity int
+
+const (
+	// The generated code is usable, but something looks wrong.
+	SeverityWarning Severity = Severity(codegen.SeverityWarning)
+
+	// The generated code won't compile.
+	SeverityError Severity = Severity(codegen.SeverityError)
+)
+
+func (s Severity) String() string {
+	return codegen.Severity(s).String()
+}
+
+// A problem with the generated code that didn't stop the generator.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+
+	// Where in the generated file the problem is, if known.
+	Pos token.Position
+
+	// The field of the API type that the code with the problem was generated
+	// for, if known, e.g., Step.Timeout.
+	Field string
+}
+
+// Formats the diagnostic like a compiler message, e.g.,
+// "error: types.go:12:3: undefined: Foo (generated for Step.Timeout)".
+func (d Diagnostic) String() string {
+	return codegen.Diagnostic{
+		Severity: codegen.Severity(d.Severity),
+		Message:  d.Message,
+		Pos:      d.Pos,
+		Field:    d.Field,
+	}.String()
+}
+
+// The result of a generator run.
+type Result struct {
+	// The generated Go file, gofmt'd.
+	Source []byte
+
+	// The name of the generated file: Options.FileName, or DefaultFileName.
+	FileName string
+
+	// Problems with the generated code.
+	//
+	// If the code couldn't be gofmt'd, Source contains the unformatted code
+	// and there's a SeverityError diagnostic explaining why.
+	Diagnostics []Diagnostic
+}
+
+// Whether any of the diagnostics are errors.
+func (r Result) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Generates the Go source for the builtins.
+//
+// Returns an error if the input package can't be loaded, or has a type
+// the generator doesn't support. Problems with the generated code itself
+// are returned as diagnostics instead, so that callers can still look at it.
+func Generate(opts Options) (Result, error) {
+	out, err := codegen.Generate(opts.codegenOptions())
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Source: out.Source, FileName: opts.FileName}
+	if result.FileName == "" {
+		result.FileName = DefaultFileName
+	}
+	for _, d := range out.Diagnostics {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: Severity(d.Severity),
+			Message:  d.Message,
+			Pos:      d.Pos,
+			Field:    d.Field,
+		})
+	}
+	return result, nil
+}
+
+// Writes the generated source into a directory, as the file named in
+// Options.FileName, and returns the path of the file.
+//
+// The write is atomic: the file is written to a temp file in the same
+// directory first, then renamed.
+func WriteFile(dir string, result Result) (string, error) {
+	return codegen.WriteOutputFile(dir, result.FileName, result.Source)
+}
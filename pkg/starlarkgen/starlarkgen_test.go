@@ -0,0 +1,123 @@
+package starlarkgen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/codegen"
+)
+
+func TestGenerate(t *testing.T) {
+	result, err := Generate(Options{
+		InputPackage:  "../../test/testdata/scalars",
+		OutputPackage: "bindings",
+		Runtime:       StandaloneRuntime,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Diagnostics)
+	assert.False(t, result.HasErrors())
+
+	src := string(result.Source)
+	assert.Contains(t, src, "package bindings\n")
+	assert.Contains(t, src, `"github.com/tilt-dev/tilt-starlark-codegen/pkg/starlarkrt"`)
+	assert.Contains(t, src, `env.AddBuiltin("scalars.server", p.server)`)
+
+	dir := t.TempDir()
+	path, err := WriteFile(dir, result)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, DefaultFileName), path)
+	written, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, result.Source, written)
+}
+
+func TestGenerateFileName(t *testing.T) {
+	result, err := Generate(Options{
+		InputPackage: "../../test/testdata/scalars",
+		Runtime:      StandaloneRuntime,
+		FileName:     "servers.go",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "servers.go", result.FileName)
+
+	dir := t.TempDir()
+	path, err := WriteFile(dir, result)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "servers.go"), path)
+}
+
+// Options is a copy of the internal options, so that its fields are
+// documented here. Make sure it doesn't fall behind.
+func TestOptionsMatchCodegen(t *testing.T) {
+	names := func(t reflect.Type) []string {
+		result := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			result = append(result, t.Field(i).Name)
+		}
+		return result
+	}
+	assert.Equal(t, names(reflect.TypeOf(codegen.Options{})), names(reflect.TypeOf(Options{})))
+}
+
+func TestGenerateUnknownType(t *testing.T) {
+	_, err := Generate(Options{
+		InputPackage: "../../test/testdata/scalars",
+		Types:        []string{"Client"},
+	})
+	assert.EqualError(t, err, "type Client not found (or not tagged with +tilt:starlark-gen=true)")
+}
+
+func TestGenerateTypeCheck(t *testing.T) {
+	result, err := Generate(Options{
+		InputPackage:  "../../test/testdata/nested",
+		OutputPackage: "bindings",
+		Runtime:       StandaloneRuntime,
+		TypeCheck:     true,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Diagnostics)
+}
+
+// The overrides break the unpackers for two fields. Compiler messages change
+// between Go versions, so only check where the errors point.
+func TestGenerateTypeCheckErrors(t *testing.T) {
+	result, err := Generate(Options{
+		InputPackage: "../../test/testdata/typecheck_errors",
+		Runtime:      StandaloneRuntime,
+		TemplateDir:  "../../test/testdata/typecheck_errors/overrides",
+		TypeCheck:    true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.HasErrors())
+
+	lines := strings.Split(string(result.Source), "\n")
+	fields := []string{}
+	for _, d := range result.Diagnostics {
+		assert.Equal(t, SeverityError, d.Severity)
+		assert.Equal(t, DefaultFileName, d.Pos.Filename)
+		require.True(t, d.Pos.Line <= len(lines), d.String())
+		assert.True(t, strings.HasPrefix(lines[d.Pos.Line-1][d.Pos.Column-1:], "[]byte(v)"), d.String())
+		fields = append(fields, d.Field)
+	}
+	assert.Equal(t, []string{"Bulb.Color", "Bulb.Watts"}, fields)
+}
+
+// This module can't import Tilt's internal packages, so the code can't
+// be checked against the Tilt runtime.
+func TestGenerateTypeCheckUnresolved(t *testing.T) {
+	result, err := Generate(Options{
+		InputPackage: "../../test/testdata/scalars",
+		TypeCheck:    true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, SeverityError, result.Diagnostics[0].Severity)
+	assert.Contains(t, result.Diagnostics[0].Message, "couldn't type-check the generated code")
+	assert.True(t, result.HasErrors())
+}
@@ -0,0 +1,95 @@
+// Package starlarkrt is a standalone implementation of the helpers that
+// code generated by tilt-starlark-codegen calls at runtime.
+//
+// It provides the same API as the parts of Tilt's internal starkit and value
+// packages that the generated code uses, so that generated bindings compile
+// outside the Tilt repo. Generate code against it with:
+//
+//	tilt-starlark-codegen generate --runtime=standalone ./path/to/input ./path/to/output
+package starlarkrt
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// A builtin implemented in Go.
+type Function func(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)
+
+// Collects builtins, so that they can be predeclared when executing Starlark code.
+type Environment struct {
+	builtins map[string]Function
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{builtins: map[string]Function{}}
+}
+
+// Adds a builtin.
+//
+// A dotted name, like "v1alpha1.file_watch", adds the builtin as a member
+// of a module.
+func (e *Environment) AddBuiltin(name string, f Function) error {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return fmt.Errorf("builtin name %q: at most one module is supported", name)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return fmt.Errorf("builtin name %q: empty name", name)
+		}
+	}
+
+	if _, exists := e.builtins[name]; exists {
+		return fmt.Errorf("builtin %q already exists", name)
+	}
+	e.builtins[name] = f
+	return nil
+}
+
+// The globals that make the builtins available to Starlark code.
+func (e *Environment) Predeclared() starlark.StringDict {
+	names := []string{}
+	for name := range e.builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := starlark.StringDict{}
+	modules := map[string]*starlarkstruct.Module{}
+	for _, name := range names {
+		parts := strings.Split(name, ".")
+		if len(parts) == 1 {
+			result[name] = starlark.NewBuiltin(name, e.builtins[name])
+			continue
+		}
+
+		moduleName, memberName := parts[0], parts[1]
+		module, ok := modules[moduleName]
+		if !ok {
+			module = &starlarkstruct.Module{Name: moduleName, Members: starlark.StringDict{}}
+			modules[moduleName] = module
+			result[moduleName] = module
+		}
+		module.Members[memberName] = starlark.NewBuiltin(name, e.builtins[name])
+	}
+	return result
+}
+
+// Executes a Starlark file with the builtins predeclared.
+//
+// Relative paths passed to builtins are resolved against the file's directory.
+func (e *Environment) ExecFile(path string) (starlark.StringDict, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &starlark.Thread{Name: absPath}
+	return starlark.ExecFile(t, absPath, nil, e.Predeclared())
+}
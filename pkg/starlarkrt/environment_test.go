@@ -0,0 +1,92 @@
+package starlarkrt
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+)
+
+type recorded struct {
+	paths    []string
+	base     string
+	labels   map[string]string
+	timeout  time.Duration
+	patterns []string
+}
+
+func TestExecFileUnpacksArgs(t *testing.T) {
+	var result recorded
+	env := NewEnvironment()
+	err := env.AddBuiltin("test.record", func(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		paths := NewLocalPathListUnpacker(t)
+		base := NewLocalPathUnpacker(t)
+		err := base.Unpack(starlark.String(""))
+		if err != nil {
+			return nil, err
+		}
+		var labels StringStringMap
+		var timeout Duration
+		var patterns StringList
+		err = UnpackArgs(t, fn.Name(), args, kwargs,
+			"paths?", &paths,
+			"base?", &base,
+			"labels?", &labels,
+			"timeout?", &timeout,
+			"patterns?", &patterns)
+		if err != nil {
+			return nil, err
+		}
+		result = recorded{paths.Value, base.Value, labels, time.Duration(timeout), patterns}
+		return starlark.None, nil
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Tiltfile")
+	err = ioutil.WriteFile(path, []byte(`
+test.record(
+  paths=['a', '/b'],
+  labels={'x': 'y'},
+  timeout='1m',
+  patterns=('*.go',))
+`), 0644)
+	require.NoError(t, err)
+
+	_, err = env.ExecFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, recorded{
+		paths:    []string{filepath.Join(dir, "a"), "/b"},
+		base:     dir,
+		labels:   map[string]string{"x": "y"},
+		timeout:  time.Minute,
+		patterns: []string{"*.go"},
+	}, result)
+}
+
+func TestUnpackErrors(t *testing.T) {
+	var list StringList
+	assert.EqualError(t, list.Unpack(starlark.String("a")), "expected list of strings, got string")
+	assert.EqualError(t, list.Unpack(starlark.NewList([]starlark.Value{starlark.MakeInt(1)})),
+		"at index 0: expected string, got int")
+
+	var m StringStringMap
+	assert.EqualError(t, m.Unpack(starlark.NewList(nil)), "expected dict, got list")
+
+	var d Duration
+	assert.Error(t, d.Unpack(starlark.String("soon")))
+}
+
+func TestAddBuiltinDuplicate(t *testing.T) {
+	env := NewEnvironment()
+	fn := func(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		return starlark.None, nil
+	}
+	require.NoError(t, env.AddBuiltin("v1.x", fn))
+	assert.EqualError(t, env.AddBuiltin("v1.x", fn), `builtin "v1.x" already exists`)
+	assert.Error(t, env.AddBuiltin("a.b.c", fn))
+}
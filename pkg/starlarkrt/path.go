@@ -0,0 +1,92 @@
+package starlarkrt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.starlark.net/starlark"
+)
+
+// The directory that relative paths are resolved against: the directory of
+// the Starlark file that's currently executing.
+//
+// Falls back to the process working directory if no Starlark file is executing.
+func AbsWorkingDir(t *starlark.Thread) string {
+	stack := t.CallStack()
+	for i := 0; i < len(stack); i++ {
+		filename := stack.At(i).Pos.Filename()
+		if filename == "" || filename == "<builtin>" {
+			continue
+		}
+		abs, err := filepath.Abs(filepath.Dir(filename))
+		if err == nil {
+			return abs
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	return wd
+}
+
+// Resolves a path against the directory of the Starlark file
+// that's currently executing.
+func AbsPath(t *starlark.Thread, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(AbsWorkingDir(t), path)
+}
+
+// A path on the local filesystem. Relative paths are resolved against the
+// directory of the Starlark file that's currently executing.
+type LocalPath struct {
+	t     *starlark.Thread
+	Value string
+}
+
+func NewLocalPathUnpacker(t *starlark.Thread) LocalPath {
+	return LocalPath{t: t}
+}
+
+func (p *LocalPath) Unpack(v starlark.Value) error {
+	s, ok := starlark.AsString(v)
+	if !ok {
+		return fmt.Errorf("expected path string, got %s", v.Type())
+	}
+	p.Value = AbsPath(p.t, s)
+	return nil
+}
+
+// A list of paths on the local filesystem. Accepts a list or tuple of strings,
+// or a single string.
+type LocalPathList struct {
+	t     *starlark.Thread
+	Value []string
+}
+
+func NewLocalPathListUnpacker(t *starlark.Thread) LocalPathList {
+	return LocalPathList{t: t}
+}
+
+func (l *LocalPathList) Unpack(v starlark.Value) error {
+	if s, ok := v.(starlark.String); ok {
+		l.Value = []string{AbsPath(l.t, string(s))}
+		return nil
+	}
+
+	var paths StringList
+	err := paths.Unpack(v)
+	if err != nil {
+		return err
+	}
+
+	l.Value = make([]string, 0, len(paths))
+	for _, p := range paths {
+		l.Value = append(l.Value, AbsPath(l.t, p))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package starlarkrt
+
+import (
+	"fmt"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// Unpacks the arguments of a builtin.
+//
+// Works the same as starlark.UnpackArgs. Takes the thread so that it has the
+// same signature as Tilt's starkit.UnpackArgs.
+func UnpackArgs(t *starlark.Thread, fnName string, args starlark.Tuple, kwargs []starlark.Tuple, pairs ...interface{}) error {
+	return starlark.UnpackArgs(fnName, args, kwargs, pairs...)
+}
+
+// A list of strings. Accepts a Starlark list or tuple.
+type StringList []string
+
+func (l *StringList) Unpack(v starlark.Value) error {
+	*l = nil
+	if v == nil || v == starlark.None {
+		return nil
+	}
+
+	iterable, ok := v.(starlark.Iterable)
+	if !ok || isString(v) {
+		return fmt.Errorf("expected list of strings, got %s", v.Type())
+	}
+
+	items := []string{}
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var item starlark.Value
+	for i := 0; iter.Next(&item); i++ {
+		s, ok := starlark.AsString(item)
+		if !ok {
+			return fmt.Errorf("at index %d: expected string, got %s", i, item.Type())
+		}
+		items = append(items, s)
+	}
+	*l = items
+	return nil
+}
+
+// A map from strings to strings. Accepts a Starlark dict.
+type StringStringMap map[string]string
+
+func (m *StringStringMap) Unpack(v starlark.Value) error {
+	*m = nil
+	if v == nil || v == starlark.None {
+		return nil
+	}
+
+	dict, ok := v.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("expected dict, got %s", v.Type())
+	}
+
+	result := make(map[string]string, dict.Len())
+	for _, item := range dict.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("key must be string, got %s", item[0].Type())
+		}
+		val, ok := starlark.AsString(item[1])
+		if !ok {
+			return fmt.Errorf("value of key %q must be string, got %s", key, item[1].Type())
+		}
+		result[key] = val
+	}
+	*m = result
+	return nil
+}
+
+// A duration. Accepts a string in the format of time.ParseDuration,
+// e.g., "5s" or "1m30s".
+type Duration time.Duration
+
+func (d *Duration) Unpack(v starlark.Value) error {
+	s, ok := starlark.AsString(v)
+	if !ok {
+		return fmt.Errorf("expected duration string (e.g., \"5s\"), got %s", v.Type())
+	}
+
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(dur)
+	return nil
+}
+
+func isString(v starlark.Value) bool {
+	_, ok := v.(starlark.String)
+	return ok
+}
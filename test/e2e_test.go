@@ -0,0 +1,211 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const e2eGoMod = `module e2e
+
+go 1.16
+
+require github.com/tilt-dev/tilt-starlark-codegen v0.0.0-00010101000000-000000000000
+
+replace github.com/tilt-dev/tilt-starlark-codegen => %s
+`
+
+// A stub of the hand-written half of the generated package, which calls
+// the register funcs of the generated files.
+const e2ePlugin = `package bindings
+
+import (
+	"github.com/tilt-dev/tilt-starlark-codegen/pkg/starlarkrt"
+	"go.starlark.net/starlark"
+)
+
+type Plugin struct {
+	objects *[]interface{}
+}
+
+func (p Plugin) register(t *starlark.Thread, obj interface{}) (starlark.Value, error) {
+	*p.objects = append(*p.objects, obj)
+	return starlark.None, nil
+}
+
+// Executes a Starlark file and returns the objects it registered.
+func Exec(path string) ([]interface{}, error) {
+	objects := []interface{}{}
+	p := Plugin{objects: &objects}
+	env := starlarkrt.NewEnvironment()
+	for _, register := range []func(*starlarkrt.Environment) error{%s} {
+		err := register(env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err := env.ExecFile(path)
+	return objects, err
+}
+`
+
+// Runs each script passed on the command line, and prints a JSON list
+// with the result of each.
+const e2eMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"e2e/bindings"
+)
+
+type object struct {
+	Type  string      ` + "`json:\"type\"`" + `
+	Value interface{} ` + "`json:\"value\"`" + `
+}
+
+type result struct {
+	Objects []object ` + "`json:\"objects,omitempty\"`" + `
+	Error   string   ` + "`json:\"error,omitempty\"`" + `
+}
+
+func main() {
+	results := []result{}
+	for _, path := range os.Args[1:] {
+		objects, err := bindings.Exec(path)
+		r := result{}
+		for _, obj := range objects {
+			r.Objects = append(r.Objects, object{Type: fmt.Sprintf("%T", obj), Value: obj})
+		}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+
+	out, err := json.Marshal(results)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
+`
+
+// Generates bindings for each test case with Starlark scripts into a temp
+// module, then runs the scripts against them.
+//
+// Each script has a golden .json file next to it with the objects the script
+// registered (or the error it failed with).
+func TestE2E(t *testing.T) {
+	for _, dir := range testCases(t) {
+		dir := dir
+		scripts, err := filepath.Glob(filepath.Join(dir, "*.star"))
+		require.NoError(t, err)
+		if len(scripts) == 0 {
+			continue
+		}
+
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			runE2ECase(t, dir)
+		})
+	}
+}
+
+func runE2ECase(t *testing.T, dir string) {
+	modDir, bindingsDir := newE2EModule(t, "p.registerSymbols")
+
+	args := append([]string{"generate", "--runtime", "standalone", "--package", "bindings"}, caseFlags(t, dir)...)
+	_, stderr, ok := runCodegenInProcess(append(args, "./"+dir, bindingsDir)...)
+	require.True(t, ok, stderr)
+
+	runE2EScripts(t, modDir, dir)
+}
+
+// Runs the scripts in a directory against the bindings in a temp module,
+// and compares the results with the golden .json files next to them.
+func runE2EScripts(t *testing.T, modDir string, dir string) {
+	scriptDir, err := filepath.Abs(dir)
+	require.NoError(t, err)
+	scripts, err := filepath.Glob(filepath.Join(scriptDir, "*.star"))
+	require.NoError(t, err)
+
+	out := bytes.NewBuffer(nil)
+	outErr := bytes.NewBuffer(nil)
+	cmd := exec.Command("go", append([]string{"run", "-mod=mod", "."}, scripts...)...)
+	cmd.Dir = modDir
+	cmd.Stdout = out
+	cmd.Stderr = outErr
+	err = cmd.Run()
+	require.NoError(t, err, "running generated bindings:\n%s", outErr.String())
+
+	results := []json.RawMessage{}
+	err = json.Unmarshal(out.Bytes(), &results)
+	require.NoError(t, err)
+	require.Len(t, results, len(scripts))
+
+	for i, script := range scripts {
+		pretty := bytes.NewBuffer(nil)
+		err := json.Indent(pretty, results[i], "", "  ")
+		require.NoError(t, err)
+
+		// Scripts resolve paths against their own directory.
+		actual := strings.Replace(pretty.String(), scriptDir, "$DIR", -1) + "\n"
+
+		assertGolden(t, strings.TrimSuffix(script, ".star")+".json", actual)
+	}
+}
+
+// Generates each type in split_files into its own file in the same
+// package, and runs the scripts in split_files/scripts against both.
+//
+// Both types have fields of the same struct, which the second file
+// leaves to the first with --external-structs.
+func TestE2ESplitFiles(t *testing.T) {
+	modDir, bindingsDir := newE2EModule(t, "p.registerBalancers", "p.registerRouters")
+
+	for _, flags := range [][]string{
+		{"--types", "Balancer", "--file-name", "balancers.go", "--register-func", "registerBalancers"},
+		{"--types", "Router", "--file-name", "routers.go", "--register-func", "registerRouters", "--external-structs", "Backend"},
+	} {
+		args := append([]string{"generate", "--runtime", "standalone", "--package", "bindings", "--typecheck"}, flags...)
+		_, stderr, ok := runCodegenInProcess(append(args, "./testdata/split_files", bindingsDir)...)
+		require.True(t, ok, stderr)
+	}
+
+	runE2EScripts(t, modDir, "testdata/split_files/scripts")
+}
+
+// Creates a temp module that depends on this repo, with a bindings package
+// for the generated code. The package's Exec calls the given register funcs.
+func newE2EModule(t *testing.T, registerFuncs ...string) (modDir string, bindingsDir string) {
+	repoDir, err := filepath.Abs("..")
+	require.NoError(t, err)
+
+	modDir = t.TempDir()
+	bindingsDir = filepath.Join(modDir, "bindings")
+	require.NoError(t, os.Mkdir(bindingsDir, 0755))
+
+	goSum, err := ioutil.ReadFile(filepath.Join(repoDir, "go.sum"))
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(modDir, "go.sum"), string(goSum))
+	writeFile(t, filepath.Join(modDir, "go.mod"), fmt.Sprintf(e2eGoMod, repoDir))
+	writeFile(t, filepath.Join(modDir, "main.go"), e2eMain)
+	writeFile(t, filepath.Join(bindingsDir, "plugin.go"), fmt.Sprintf(e2ePlugin, strings.Join(registerFuncs, ", ")))
+	return modDir, bindingsDir
+}
+
+func writeFile(t *testing.T, path string, contents string) {
+	err := ioutil.WriteFile(path, []byte(contents), 0644)
+	require.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runStarlarkGen(args ...string) (stderr string, err error) {
+	outErr := bytes.NewBuffer(nil)
+	cmd := exec.Command("go", append([]string{"run", "../cmd/starlark-gen"}, args...)...)
+	cmd.Stderr = outErr
+	err = cmd.Run()
+	return outErr.String(), err
+}
+
+// Runs the gengo generator the way a hack/update-codegen.sh script would,
+// then checks that the output compiles.
+func TestStarlarkGen(t *testing.T) {
+	repoDir, err := filepath.Abs("..")
+	require.NoError(t, err)
+
+	modDir := t.TempDir()
+	goSum, err := ioutil.ReadFile(filepath.Join(repoDir, "go.sum"))
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(modDir, "go.sum"), string(goSum))
+	writeFile(t, filepath.Join(modDir, "go.mod"), fmt.Sprintf(e2eGoMod, repoDir))
+
+	args := []string{
+		"--input-dirs", "github.com/tilt-dev/tilt-starlark-codegen/test/testdata/example",
+		"--output-base", modDir,
+		"--output-package", "e2e",
+		"--runtime", "standalone",
+	}
+	stderr, err := runStarlarkGen(args...)
+	require.NoError(t, err, stderr)
+
+	outDir := filepath.Join(modDir, "e2e", "example")
+	contents, err := ioutil.ReadFile(filepath.Join(outDir, "types.go"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(contents), "// Code generated by starlark-gen. DO NOT EDIT.\n"))
+	assert.Contains(t, string(contents), `env.AddBuiltin("example.file_watch", p.fileWatch)`)
+
+	plugin := strings.Replace(fmt.Sprintf(e2ePlugin, "p.registerSymbols"), "package bindings", "package example", 1)
+	writeFile(t, filepath.Join(outDir, "plugin.go"), plugin)
+
+	cmd := exec.Command("go", "vet", "-mod=mod", "./...")
+	cmd.Dir = modDir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "compiling generated code:\n%s", out)
+
+	stderr, err = runStarlarkGen(append(args, "--verify-only")...)
+	require.NoError(t, err, stderr)
+
+	err = os.Remove(filepath.Join(outDir, "types.go"))
+	require.NoError(t, err)
+	_, err = runStarlarkGen(append(args, "--verify-only")...)
+	assert.Error(t, err)
+}
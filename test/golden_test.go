@@ -4,40 +4,124 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt-starlark-codegen/internal/cli"
 )
 
+// Each directory in testdata is a test case: an API package, and the code we
+// expect to generate for it in golden.txt.
+//
+// If a case needs extra flags, list them in a file named "flags" in the case
+// directory. If generation is expected to fail, golden.txt holds the error
+// output instead.
+//
+// Run with WRITE_GOLDEN_MASTER=1 to update the golden files.
 func TestGolden(t *testing.T) {
+	for _, dir := range testCases(t) {
+		dir := dir
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			args := append([]string{"generate", "--stdout"}, caseFlags(t, dir)...)
+			stdout, stderr, ok := runCodegenInProcess(append(args, "./"+dir)...)
+
+			actual := stdout
+			if !ok {
+				actual = stderr
+			}
+			assertGolden(t, filepath.Join(dir, "golden.txt"), actual)
+		})
+	}
+}
+
+// The list subcommand prints a table of builtins, in list.txt.
+func TestList(t *testing.T) {
+	for _, name := range []string{"example", "nested", "maps"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("testdata", name)
+			stdout, stderr, ok := runCodegenInProcess("list", "./"+dir)
+			require.True(t, ok, stderr)
+			assertGolden(t, filepath.Join(dir, "list.txt"), stdout)
+		})
+	}
+}
+
+// The explain subcommand describes one builtin, in explain_<type>.txt.
+// Types may be named by Go type or builtin name, so the cases use both.
+func TestExplain(t *testing.T) {
+	cases := []struct {
+		name string
+		typ  string
+	}{
+		{"example", "FileWatch"},
+		{"example", "ignore_def"},
+		{"example", "Missing"},
+		{"nested", "nested.step"},
+		{"maps", "Backend"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name+"/"+c.typ, func(t *testing.T) {
+			dir := filepath.Join("testdata", c.name)
+			stdout, stderr, ok := runCodegenInProcess("explain", "./"+dir, c.typ)
+
+			actual := stdout
+			if !ok {
+				actual = stderr
+			}
+			assertGolden(t, filepath.Join(dir, "explain_"+c.typ+".txt"), actual)
+		})
+	}
+}
+
+// All the test case directories.
+func testCases(t *testing.T) []string {
+	entries, err := ioutil.ReadDir("testdata")
+	require.NoError(t, err)
+
+	result := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			result = append(result, filepath.Join("testdata", entry.Name()))
+		}
+	}
+	return result
+}
+
+// Extra flags for a test case.
+func caseFlags(t *testing.T, dir string) []string {
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "flags"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	return strings.Fields(string(contents))
+}
+
+// Runs the command line in this process, which is much faster than
+// compiling it for each case.
+func runCodegenInProcess(args ...string) (stdout string, stderr string, ok bool) {
 	out := bytes.NewBuffer(nil)
 	outErr := bytes.NewBuffer(nil)
-	cmd := exec.Command("go", "run", "../main.go", "./example", "-")
-	cmd.Stdout = out
-	cmd.Stderr = outErr
-
-	err := cmd.Run()
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "%s\n", outErr.String())
-		t.Fatalf("codegen failed: %v", err)
-	}
+	code := cli.Main(append([]string{"tilt-starlark-codegen"}, args...), out, outErr)
+	return out.String(), outErr.String(), code == 0
+}
 
-	log.Println(os.Getwd())
-	goldenPath := filepath.Join("golden", "master.txt")
-	write := os.Getenv("WRITE_GOLDEN_MASTER") != ""
-	if write {
-		err = ioutil.WriteFile(goldenPath, out.Bytes(), os.FileMode(0644))
+func assertGolden(t *testing.T, goldenPath string, actual string) {
+	if os.Getenv("WRITE_GOLDEN_MASTER") != "" {
+		err := ioutil.WriteFile(goldenPath, []byte(actual), os.FileMode(0644))
 		require.NoError(t, err)
-		fmt.Println("GENERATED GOLDEN MASTER")
+		fmt.Printf("GENERATED GOLDEN MASTER %s\n", goldenPath)
 		return
 	}
 
 	golden, err := ioutil.ReadFile(goldenPath)
 	assert.NoError(t, err)
-	assert.Equal(t, string(golden), out.String())
+	assert.Equal(t, string(golden), actual)
 }
@@ -0,0 +1,36 @@
+// Generated code that calls a runtime at non-default import paths.
+package custom_runtime
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Deploy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeploySpec `json:"spec,omitempty"`
+}
+
+type DeploySpec struct {
+	// +tilt:local-path=true
+	Dir string `json:"dir,omitempty"`
+
+	// +tilt:local-path=true
+	Deps []string `json:"deps,omitempty"`
+
+	Command []string          `json:"command,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Timeout metav1.Duration   `json:"timeout,omitempty"`
+	Hooks   []Hook            `json:"hooks,omitempty"`
+}
+
+type Hook struct {
+	Command []string `json:"command,omitempty"`
+
+	// +tilt:local-path=true
+	Dir string `json:"dir,omitempty"`
+
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
@@ -0,0 +1,39 @@
+// Field names that would make bad local variables: keywords, predeclared
+// identifiers, packages, and the locals the generated code already uses.
+package identifiers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Job struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec JobSpec `json:"spec,omitempty"`
+}
+
+type JobSpec struct {
+	Type   JobType  `json:"type,omitempty"`
+	Func   string   `json:"func,omitempty"`
+	Range  []string `json:"range,omitempty"`
+	Err    string   `json:"err,omitempty"`
+	Obj    string   `json:"obj,omitempty"`
+	Value  []string `json:"value,omitempty"`
+	Args   []string `json:"args,omitempty"`
+	String string   `json:"string,omitempty"`
+	Fmt    string   `json:"fmt,omitempty"`
+	Knob   *Knob    `json:"knob,omitempty"`
+}
+
+type JobType string
+
+type Knob struct {
+	Dict  string `json:"dict,omitempty"`
+	Key   string `json:"key,omitempty"`
+	T     int32  `json:"t,omitempty"`
+	Ok    bool   `json:"ok,omitempty"`
+	Len   int32  `json:"len,omitempty"`
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,42 @@
+// Argument names that come from json tags, with --arg-names json.
+package json_names
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Deploy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeploySpec `json:"spec,omitempty"`
+}
+
+type DeploySpec struct {
+	// Serialized as image, so the argument is image, not image_ref.
+	ImageRef string `json:"image"`
+
+	PodIPs []string `json:"podIPs,omitempty"`
+
+	// No name in the tag, so the argument is named after the Go field.
+	Replicas int32 `json:",omitempty"`
+
+	Strategy DeployStrategy `json:"strategy,omitempty"`
+
+	// Not serialized, so it's not an argument either, and State gets no unpacker.
+	Cache *State `json:"-"`
+}
+
+type DeployStrategy struct {
+	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
+
+	// The tag wins over the json name.
+	//
+	// +tilt:starlark-name=mode
+	Type string `json:"strategyType,omitempty"`
+}
+
+type State struct {
+	Hits map[string]int64 `json:"hits,omitempty"`
+}
@@ -0,0 +1,18 @@
+// A field whose argument name collides with the object's labels argument.
+package collision
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Pod struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PodSpec `json:"spec,omitempty"`
+}
+
+type PodSpec struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
@@ -0,0 +1,32 @@
+// Maps from strings to values other than strings.
+package maps
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Router struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouterSpec `json:"spec,omitempty"`
+}
+
+type RouterSpec struct {
+	Weights  map[string]int64     `json:"weights,omitempty"`
+	Backends map[string]Backend   `json:"backends,omitempty"`
+	Hosts    map[string][]string  `json:"hosts,omitempty"`
+	Pools    map[string][]Backend `json:"pools,omitempty"`
+	Env      map[string]string    `json:"env,omitempty"`
+	Modes    map[string]Mode      `json:"modes,omitempty"`
+}
+
+type Mode string
+
+type Backend struct {
+	Address string             `json:"address,omitempty"`
+	Ports   map[string][]int32 `json:"ports,omitempty"`
+	Enabled map[string]bool    `json:"enabled,omitempty"`
+	Weights map[string]int64   `json:"weights,omitempty"`
+}
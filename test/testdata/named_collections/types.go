@@ -0,0 +1,62 @@
+// Named types over slices and maps.
+package named_collections
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Job struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec JobSpec `json:"spec,omitempty"`
+}
+
+type JobSpec struct {
+	Args     Args     `json:"args,omitempty"`
+	Env      EnvVars  `json:"env,omitempty"`
+	Selector Selector `json:"selector,omitempty"`
+	Ports    Ports    `json:"ports,omitempty"`
+
+	// +tilt:local-path=true
+	Inputs Paths `json:"inputs,omitempty"`
+
+	Tasks []Task `json:"tasks,omitempty"`
+}
+
+type Task struct {
+	Args     Args               `json:"args,omitempty"`
+	Env      EnvVars            `json:"env,omitempty"`
+	Selector Selector           `json:"selector,omitempty"`
+	Weights  Weights            `json:"weights,omitempty"`
+	Probes   Probes             `json:"probes,omitempty"`
+	Profiles map[string]Args    `json:"profiles,omitempty"`
+	EnvSets  map[string]EnvVars `json:"envSets,omitempty"`
+
+	// +tilt:local-path=true
+	Outputs Paths `json:"outputs,omitempty"`
+}
+
+type Args []string
+
+type Paths []string
+
+type Ports []int32
+
+type EnvVars []EnvVar
+
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type Selector map[string]string
+
+type Weights map[string]int64
+
+type Probes []*Probe
+
+type Probe struct {
+	Path string `json:"path,omitempty"`
+}
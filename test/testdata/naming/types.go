@@ -0,0 +1,33 @@
+// Go names with initialisms, converted with the default initialisms and the
+// ones in naming.json.
+package naming
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type HTTPProbe struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HTTPProbeSpec `json:"spec,omitempty"`
+}
+
+type HTTPProbeSpec struct {
+	HTTPGet   *HTTPGetAction  `json:"httpGet,omitempty"`
+	TCPSocket *TCPSocketCheck `json:"tcpSocket,omitempty"`
+
+	// pod_ips, because naming.json adds IPs.
+	PodIPs []string `json:"podIPs,omitempty"`
+}
+
+type HTTPGetAction struct {
+	URLPath string `json:"urlPath,omitempty"`
+	Port    int32  `json:"port,omitempty"`
+}
+
+type TCPSocketCheck struct {
+	IPAddress string `json:"ipAddress,omitempty"`
+	Port      int32  `json:"port,omitempty"`
+}
@@ -0,0 +1,64 @@
+package nested
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Build is a fixture for structs nested in structs.
+//
+// +tilt:starlark-gen=true
+type Build struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BuildSpec `json:"spec,omitempty"`
+}
+
+type BuildSpec struct {
+	// +tilt:local-path=true
+	Context string `json:"context"`
+
+	Steps []Step `json:"steps,omitempty"`
+	Cache *Cache `json:"cache,omitempty"`
+}
+
+type Step struct {
+	Command []string `json:"command"`
+
+	// +tilt:local-path=true
+	Dir string `json:"dir,omitempty"`
+
+	// +tilt:local-path=true
+	Inputs []string `json:"inputs,omitempty"`
+
+	Env     map[string]string `json:"env,omitempty"`
+	Timeout metav1.Duration   `json:"timeout,omitempty"`
+	Retry   *Retry            `json:"retry,omitempty"`
+
+	// Status fields are ignored.
+	StartTime metav1.MicroTime `json:"startTime,omitempty"`
+}
+
+type Retry struct {
+	Attempts *int32    `json:"attempts,omitempty"`
+	Backoff  *string   `json:"backoff,omitempty"`
+	Jitter   *bool     `json:"jitter,omitempty"`
+	Mode     RetryMode `json:"mode,omitempty"`
+}
+
+type RetryMode string
+
+type Cache struct {
+	CacheTarget `json:",inline"`
+
+	Disabled Toggle `json:"disabled,omitempty"`
+}
+
+type CacheTarget struct {
+	Key string `json:"key,omitempty"`
+
+	// +tilt:local-path=true
+	Paths []string `json:"paths,omitempty"`
+}
+
+type Toggle bool
@@ -0,0 +1,41 @@
+// Integer and float fields of every width.
+package numbers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Limiter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LimiterSpec `json:"spec,omitempty"`
+}
+
+type LimiterSpec struct {
+	TimeoutSeconds int64   `json:"timeoutSeconds,omitempty"`
+	Ratio          float64 `json:"ratio,omitempty"`
+	Scale          float32 `json:"scale,omitempty"`
+	Share          Share   `json:"share,omitempty"`
+	Burst          uint    `json:"burst,omitempty"`
+	Window         Window  `json:"window,omitempty"`
+}
+
+type Window struct {
+	Size       int64    `json:"size,omitempty"`
+	Port       uint16   `json:"port,omitempty"`
+	Retries    *uint32  `json:"retries,omitempty"`
+	Weight     float32  `json:"weight,omitempty"`
+	Ratio      float64  `json:"ratio,omitempty"`
+	Budget     *float64 `json:"budget,omitempty"`
+	Generation Sequence `json:"generation,omitempty"`
+	Count      int      `json:"count,omitempty"`
+	Offset     int16    `json:"offset,omitempty"`
+	Total      uint64   `json:"total,omitempty"`
+	Index      int32    `json:"index,omitempty"`
+}
+
+type Sequence int64
+
+type Share float64
@@ -0,0 +1,35 @@
+// Slices of pointers to structs.
+package pointer_lists
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Service struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceSpec `json:"spec,omitempty"`
+}
+
+type ServiceSpec struct {
+	Probes     []*Probe    `json:"probes,omitempty"`
+	Containers []Container `json:"containers,omitempty"`
+}
+
+type Container struct {
+	Image  string   `json:"image,omitempty"`
+	Probes []*Probe `json:"probes,omitempty"`
+}
+
+type Probe struct {
+	Path    string    `json:"path,omitempty"`
+	Port    int32     `json:"port,omitempty"`
+	Headers []*Header `json:"headers,omitempty"`
+}
+
+type Header struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
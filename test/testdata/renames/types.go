@@ -0,0 +1,29 @@
+// Fields whose default argument names collide, resolved with --rename.
+package renames
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouteSpec `json:"spec,omitempty"`
+}
+
+type RouteSpec struct {
+	// Collides with the object's name.
+	Name string `json:"name,omitempty"`
+
+	// Both are url_path by default.
+	URLPath string `json:"urlPath,omitempty"`
+	UrlPath string `json:"legacyUrlPath,omitempty"`
+
+	Backend Backend `json:"backend,omitempty"`
+}
+
+type Backend struct {
+	Labels []string `json:"labels,omitempty"`
+}
@@ -0,0 +1,33 @@
+// Fields named like the runtime packages, which are imported by
+// the last element of their custom paths.
+package runtime_shadowing
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Watch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WatchSpec `json:"spec,omitempty"`
+}
+
+type WatchSpec struct {
+	// +tilt:local-path=true
+	Paths []string `json:"paths,omitempty"`
+
+	Ignores  []string        `json:"ignores,omitempty"`
+	Hooks    []Hook          `json:"hooks,omitempty"`
+	Debounce metav1.Duration `json:"debounce,omitempty"`
+
+	// Named like the API package's import alias.
+	Runtimeshadowing string `json:"runtimeshadowing,omitempty"`
+}
+
+type Hook struct {
+	Ignores []string        `json:"ignores,omitempty"`
+	Hooks   []string        `json:"hooks,omitempty"`
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
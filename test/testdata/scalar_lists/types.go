@@ -0,0 +1,27 @@
+// Slices of bools and numbers.
+package scalar_lists
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Balancer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BalancerSpec `json:"spec,omitempty"`
+}
+
+type BalancerSpec struct {
+	Ports    []int32   `json:"ports,omitempty"`
+	Enabled  []bool    `json:"enabled,omitempty"`
+	Backends []Backend `json:"backends,omitempty"`
+}
+
+type Backend struct {
+	Host    string    `json:"host,omitempty"`
+	Ports   []int32   `json:"ports,omitempty"`
+	Weights []float64 `json:"weights,omitempty"`
+	Shards  []uint16  `json:"shards,omitempty"`
+}
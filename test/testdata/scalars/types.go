@@ -0,0 +1,32 @@
+package scalars
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Server is a fixture for builtin and pointer fields.
+//
+// +tilt:starlark-gen=true
+type Server struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServerSpec `json:"spec,omitempty"`
+}
+
+type ServerSpec struct {
+	Port    int32           `json:"port,omitempty"`
+	Enabled bool            `json:"enabled,omitempty"`
+	Mode    ServerMode      `json:"mode,omitempty"`
+	Probe   *Probe          `json:"probe,omitempty"`
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+type ServerMode string
+
+type Probe struct {
+	Path     string     `json:"path,omitempty"`
+	Port     int32      `json:"port,omitempty"`
+	Insecure bool       `json:"insecure,omitempty"`
+	Mode     ServerMode `json:"mode,omitempty"`
+}
@@ -0,0 +1,37 @@
+// A naming config shared by runs that generate different types.
+package shared_naming_config
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouteSpec `json:"spec,omitempty"`
+}
+
+type RouteSpec struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// +tilt:starlark-gen=true
+type Tunnel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TunnelSpec `json:"spec,omitempty"`
+}
+
+type TunnelSpec struct {
+	Port    int32   `json:"port,omitempty"`
+	Backend Backend `json:"backend,omitempty"`
+}
+
+// Only reachable from Tunnel.
+type Backend struct {
+	Host string `json:"host,omitempty"`
+}
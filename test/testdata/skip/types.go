@@ -0,0 +1,39 @@
+// Fields tagged +tilt:starlark-skip, which aren't part of the Starlark API.
+package skip
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Service struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceSpec `json:"spec,omitempty"`
+}
+
+type ServiceSpec struct {
+	Port Port `json:"port,omitempty"`
+
+	// Set by the controller, not by users.
+	//
+	// +tilt:starlark-skip
+	Generation int64 `json:"generation,omitempty"`
+
+	// Only reachable through a skipped field, so it gets no unpacker.
+	//
+	// +tilt:starlark-skip
+	Cache *CacheState `json:"cache,omitempty"`
+}
+
+type Port struct {
+	Number int32 `json:"number,omitempty"`
+
+	// +tilt:starlark-skip
+	Weights map[string]int32 `json:"weights,omitempty"`
+}
+
+type CacheState struct {
+	Hits map[string]int64 `json:"hits,omitempty"`
+}
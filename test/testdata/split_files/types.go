@@ -0,0 +1,48 @@
+// Types that are generated into separate files in the same package, with
+// --types, --file-name, and --register-func. Both have fields of type
+// Backend, which only one of the files generates.
+package split_files
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Balancer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BalancerSpec `json:"spec,omitempty"`
+}
+
+type BalancerSpec struct {
+	Ports    []int32          `json:"ports,omitempty"`
+	Enabled  []bool           `json:"enabled,omitempty"`
+	Weights  map[string]int64 `json:"weights,omitempty"`
+	Backends []Backend        `json:"backends,omitempty"`
+}
+
+// +tilt:starlark-gen=true
+type Router struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouterSpec `json:"spec,omitempty"`
+}
+
+type RouterSpec struct {
+	Ports   []int32            `json:"ports,omitempty"`
+	Weights map[string]int64   `json:"weights,omitempty"`
+	Hosts   map[string][]int32 `json:"hosts,omitempty"`
+	Default *Backend           `json:"default,omitempty"`
+	ByHost  map[string]Backend `json:"byHost,omitempty"`
+}
+
+type Backend struct {
+	Host  string `json:"host,omitempty"`
+	Retry *Retry `json:"retry,omitempty"`
+}
+
+type Retry struct {
+	Attempts int32 `json:"attempts,omitempty"`
+}
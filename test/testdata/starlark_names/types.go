@@ -0,0 +1,35 @@
+// Fields whose argument names are set with +tilt:starlark-name.
+package starlark_names
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Job struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec JobSpec `json:"spec,omitempty"`
+}
+
+type JobSpec struct {
+	// The command to run.
+	//
+	// +tilt:starlark-name=cmd
+	Command []string `json:"command,omitempty"`
+
+	// +tilt:starlark-name=workdir
+	WorkingDirectory string `json:"workingDirectory,omitempty"`
+
+	// +tilt:starlark-name=retry
+	RetryPolicy RetryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type RetryPolicy struct {
+	// +tilt:starlark-name=max
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// +tilt:starlark-name=backoff
+	BackoffStrategy string `json:"backoffStrategy,omitempty"`
+}
@@ -0,0 +1,25 @@
+// Generated with --templates, which overrides the attr template
+// to name the attribute in type errors.
+package templates
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Gadget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GadgetSpec `json:"spec,omitempty"`
+}
+
+type GadgetSpec struct {
+	Dial Dial `json:"dial"`
+}
+
+type Dial struct {
+	Label string `json:"label,omitempty"`
+	Level int32  `json:"level,omitempty"`
+	On    bool   `json:"on,omitempty"`
+}
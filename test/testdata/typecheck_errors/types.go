@@ -0,0 +1,24 @@
+// Generated with --typecheck, and an attr template that converts
+// every scalar to the wrong type, so that type-checking fails.
+package typecheck
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Lamp struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LampSpec `json:"spec,omitempty"`
+}
+
+type LampSpec struct {
+	Bulb Bulb `json:"bulb"`
+}
+
+type Bulb struct {
+	Color string `json:"color,omitempty"`
+	Watts int32  `json:"watts,omitempty"`
+}
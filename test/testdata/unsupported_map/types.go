@@ -0,0 +1,18 @@
+// A map whose values can't be unpacked, which fails at generation time.
+package unsupported_map
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +tilt:starlark-gen=true
+type Cache struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CacheSpec `json:"spec,omitempty"`
+}
+
+type CacheSpec struct {
+	Entries map[string]map[string]string `json:"entries,omitempty"`
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCodegen(args ...string) (stdout string, stderr string, err error) {
+	out := bytes.NewBuffer(nil)
+	outErr := bytes.NewBuffer(nil)
+	cmd := exec.Command("go", append([]string{"run", "../main.go"}, args...)...)
+	cmd.Stdout = out
+	cmd.Stderr = outErr
+	err = cmd.Run()
+	return out.String(), outErr.String(), err
+}
+
+func TestVerify(t *testing.T) {
+	outDir := t.TempDir()
+	_, stderr, err := runCodegen("generate", "./testdata/example", outDir)
+	require.NoError(t, err, stderr)
+
+	stdout, stderr, err := runCodegen("verify", "./testdata/example", outDir)
+	require.NoError(t, err, stderr)
+	assert.Equal(t, "", stdout)
+	assert.Contains(t, stderr, "is up to date")
+
+	outPath := filepath.Join(outDir, "types.go")
+	contents, err := ioutil.ReadFile(outPath)
+	require.NoError(t, err)
+	contents = bytes.Replace(contents, []byte(`"watched_paths?"`), []byte(`"paths?"`), 1)
+	err = ioutil.WriteFile(outPath, contents, 0644)
+	require.NoError(t, err)
+
+	stdout, stderr, err = runCodegen("generate", "--verify", "./testdata/example", outDir)
+	require.Error(t, err)
+	assert.Contains(t, stdout, `-		"paths?", &watchedPaths,`)
+	assert.Contains(t, stdout, `+		"watched_paths?", &watchedPaths,`)
+	assert.Contains(t, stderr, "is out of date")
+	assert.Contains(t, stderr, "with the same flags")
+
+	// --diff shows the same diff, but doesn't fail.
+	stdout, stderr, err = runCodegen("generate", "--diff", "./testdata/example", outDir)
+	require.NoError(t, err, stderr)
+	assert.Contains(t, stdout, `+		"watched_paths?", &watchedPaths,`)
+}
+
+func TestGenerateWritesFile(t *testing.T) {
+	outDir := t.TempDir()
+	outPath := filepath.Join(outDir, "starlark_types.go")
+
+	// Files written by older versions were read-only.
+	err := ioutil.WriteFile(outPath, []byte("stale"), 0555)
+	require.NoError(t, err)
+
+	_, stderr, err := runCodegen("generate", "--file-name", "starlark_types.go", "./testdata/example", outDir)
+	require.NoError(t, err, stderr)
+
+	info, err := os.Stat(outPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// No temp files left behind.
+	entries, err := ioutil.ReadDir(outDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "starlark_types.go", entries[0].Name())
+}
+
+// --typecheck fails if the code can't be checked, e.g., because this module
+// can't import the Tilt runtime, instead of writing unchecked code.
+func TestTypeCheckUnresolvedFails(t *testing.T) {
+	outDir := t.TempDir()
+	_, stderr, ok := runCodegenInProcess("generate", "--typecheck", "./testdata/example", outDir)
+	assert.False(t, ok)
+	assert.Contains(t, stderr, "couldn't type-check the generated code")
+
+	entries, err := ioutil.ReadDir(outDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}